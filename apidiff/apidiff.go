@@ -0,0 +1,185 @@
+// Package apidiff reports breaking changes to the exported API of Go packages between two versions.
+package apidiff
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// Change kinds.
+const (
+	// Removed identifiers no longer exist in the new version.
+	Removed = "removed"
+	// Changed identifiers exist in both versions with incompatible types.
+	Changed = "changed"
+)
+
+// Change is a breaking change to an exported identifier of a package.
+type Change struct {
+	// Package is the import path of the changed package.
+	Package string
+
+	// Name is the identifier, "<type>.<member>" for methods and fields, or empty when the
+	// whole package was removed.
+	Name string
+
+	// Kind is Removed or Changed.
+	Kind string
+
+	// From and To describe the identifier's type in each version, To is empty when removed.
+	From string
+	To   string
+}
+
+func (c Change) String() string {
+	name := c.Package
+	if c.Name != "" {
+		name = fmt.Sprintf("%s.%s", c.Package, c.Name)
+	}
+	if c.Kind == Changed {
+		return fmt.Sprintf("%s %s: %s -> %s", c.Kind, name, c.From, c.To)
+	}
+	return fmt.Sprintf("%s %s", c.Kind, name)
+}
+
+// Compare returns the breaking changes from the old to the new version of a package, sorted by
+// name. Additions are compatible and not reported, except for methods added to interfaces.
+func Compare(old, new *types.Package) []Change {
+	oldQ, newQ := types.RelativeTo(old), types.RelativeTo(new)
+
+	changes := []Change{}
+	for _, name := range old.Scope().Names() {
+		o := old.Scope().Lookup(name)
+		if !o.Exported() {
+			continue
+		}
+
+		n := new.Scope().Lookup(name)
+		if n == nil {
+			changes = append(changes, Change{Package: old.Path(), Name: name, Kind: Removed, From: describe(o, oldQ)})
+			continue
+		}
+
+		from, to := describe(o, oldQ), describe(n, newQ)
+		if from != to {
+			changes = append(changes, Change{Package: old.Path(), Name: name, Kind: Changed, From: from, To: to})
+			continue
+		}
+
+		if _, ok := o.(*types.TypeName); ok {
+			changes = append(changes, compareMembers(old.Path(), name, o.Type(), n.Type(), oldQ, newQ)...)
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+// describe returns the kind and type of an object, comparable between versions. Struct types are
+// left out, their fields are compared separately.
+func describe(obj types.Object, q types.Qualifier) string {
+	switch obj := obj.(type) {
+	case *types.Const:
+		return "const " + types.TypeString(obj.Type(), q)
+	case *types.Var:
+		return "var " + types.TypeString(obj.Type(), q)
+	case *types.Func:
+		return "func " + types.TypeString(obj.Type(), q)
+	case *types.TypeName:
+		if obj.IsAlias() {
+			return "type = " + types.TypeString(obj.Type(), q)
+		}
+		if _, ok := obj.Type().Underlying().(*types.Struct); ok {
+			return "type struct"
+		}
+		return "type " + types.TypeString(obj.Type().Underlying(), q)
+	}
+	return fmt.Sprintf("%T", obj)
+}
+
+// compareMembers compares the exported fields and methods of a type.
+func compareMembers(pkg, name string, old, new types.Type, oldQ, newQ types.Qualifier) []Change {
+	changes := []Change{}
+
+	if oldStruct, ok := old.Underlying().(*types.Struct); ok {
+		newStruct := new.Underlying().(*types.Struct)
+		newFields := map[string]*types.Var{}
+		for i := 0; i < newStruct.NumFields(); i++ {
+			newFields[newStruct.Field(i).Name()] = newStruct.Field(i)
+		}
+		for i := 0; i < oldStruct.NumFields(); i++ {
+			f := oldStruct.Field(i)
+			if !f.Exported() {
+				continue
+			}
+			member := name + "." + f.Name()
+			from := types.TypeString(f.Type(), oldQ)
+			nf, ok := newFields[f.Name()]
+			if !ok || !nf.Exported() {
+				changes = append(changes, Change{Package: pkg, Name: member, Kind: Removed, From: from})
+				continue
+			}
+			if to := types.TypeString(nf.Type(), newQ); from != to {
+				changes = append(changes, Change{Package: pkg, Name: member, Kind: Changed, From: from, To: to})
+			}
+		}
+	}
+
+	if _, ok := old.Underlying().(*types.Interface); ok {
+		// interface methods are part of the type compared by describe
+		return changes
+	}
+
+	oldMethods := types.NewMethodSet(types.NewPointer(old))
+	newMethods := types.NewMethodSet(types.NewPointer(new))
+	for i := 0; i < oldMethods.Len(); i++ {
+		m := oldMethods.At(i).Obj()
+		if !m.Exported() {
+			continue
+		}
+		member := name + "." + m.Name()
+		from := types.TypeString(m.Type(), oldQ)
+		sel := newMethods.Lookup(m.Pkg(), m.Name())
+		if sel == nil {
+			changes = append(changes, Change{Package: pkg, Name: member, Kind: Removed, From: from})
+			continue
+		}
+		if to := types.TypeString(sel.Obj().Type(), newQ); from != to {
+			changes = append(changes, Change{Package: pkg, Name: member, Kind: Changed, From: from, To: to})
+		}
+	}
+	return changes
+}
+
+// Referenced returns the changes to identifiers in refs, formatted as "<import path>.<identifier>".
+// Changes to methods and fields are referenced through their type, and removed packages through
+// any of their identifiers.
+func Referenced(changes []Change, refs []string) []Change {
+	referenced := map[string]bool{}
+	packages := map[string]bool{}
+	for _, ref := range refs {
+		referenced[ref] = true
+		if i := strings.LastIndex(ref, "."); i > 0 {
+			packages[ref[:i]] = true
+		}
+	}
+
+	filtered := []Change{}
+	for _, c := range changes {
+		if c.Name == "" {
+			if packages[c.Package] {
+				filtered = append(filtered, c)
+			}
+			continue
+		}
+		top := strings.SplitN(c.Name, ".", 2)[0]
+		if referenced[c.Package+"."+top] {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
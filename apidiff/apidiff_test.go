@@ -0,0 +1,124 @@
+package apidiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const oldSource = `package foo
+
+import (
+	"context"
+	"net/http"
+)
+
+const Version = "1.0.0"
+
+var DefaultClient = &Client{}
+
+type Client struct {
+	HTTP    *http.Client
+	Retries int
+	Timeout int
+	secret  string
+}
+
+func New() *Client { return &Client{} }
+
+func (c *Client) Do(ctx context.Context, path string) error { return nil }
+
+func (c *Client) Close() error { return nil }
+
+type Doer interface {
+	Do(ctx context.Context, path string) error
+}
+
+type Mode int
+
+func Removed() {}
+
+func unexported() {}
+`
+
+const newSource = `package foo
+
+import (
+	"context"
+	"net/http"
+)
+
+const Version = "2.0.0"
+
+var DefaultClient = &Client{}
+
+type Client struct {
+	HTTP    *http.Client
+	Retries int64
+	Extra   bool
+}
+
+func New(opts ...Option) *Client { return &Client{} }
+
+func (c *Client) Do(ctx context.Context, path string) error { return nil }
+
+type Option func(*Client)
+
+type Doer interface {
+	Do(ctx context.Context, path string) error
+	Close() error
+}
+
+type Mode string
+
+func Added() {}
+`
+
+func TestCompare(t *testing.T) {
+	assert := require.New(t)
+
+	old, err := Check("github.com/foo/foo", map[string][]byte{
+		"foo.go":      []byte(oldSource),
+		"foo_test.go": []byte("package foo_test\n"),
+		"ignored.go":  []byte("// +build ignore\n\npackage main\n"),
+	})
+	assert.NoError(err)
+	new, err := Check("github.com/foo/foo", map[string][]byte{"foo.go": []byte(newSource)})
+	assert.NoError(err)
+
+	changes := Compare(old, new)
+	assert.Equal([]Change{
+		{Package: "github.com/foo/foo", Name: "Client.Close", Kind: Removed, From: "func() error"},
+		{Package: "github.com/foo/foo", Name: "Client.Retries", Kind: Changed, From: "int", To: "int64"},
+		{Package: "github.com/foo/foo", Name: "Client.Timeout", Kind: Removed, From: "int"},
+		{
+			Package: "github.com/foo/foo", Name: "Doer", Kind: Changed,
+			From: "type interface{Do(ctx context.Context, path string) error}",
+			To:   "type interface{Close() error; Do(ctx context.Context, path string) error}",
+		},
+		{Package: "github.com/foo/foo", Name: "Mode", Kind: Changed, From: "type int", To: "type string"},
+		{Package: "github.com/foo/foo", Name: "New", Kind: Changed, From: "func func() *Client", To: "func func(opts ...Option) *Client"},
+		{Package: "github.com/foo/foo", Name: "Removed", Kind: Removed, From: "func func()"},
+	}, changes)
+
+	assert.Equal([]Change{changes[0], changes[1], changes[2], changes[5]}, Referenced(changes, []string{
+		"github.com/foo/foo.Client",
+		"github.com/foo/foo.New",
+		"github.com/foo/foo.Added",
+	}))
+
+	assert.Equal("removed github.com/foo/foo.Removed", changes[6].String())
+	assert.Equal("changed github.com/foo/foo.Mode: type int -> type string", changes[4].String())
+}
+
+func TestReferenced_Package(t *testing.T) {
+	changes := []Change{{Package: "github.com/foo/foo/bar", Kind: Removed}}
+
+	require.Equal(t, changes, Referenced(changes, []string{"github.com/foo/foo/bar.Baz"}))
+	require.Equal(t, []Change{}, Referenced(changes, []string{"github.com/foo/foo.Baz"}))
+}
+
+func TestCheck_NoPackage(t *testing.T) {
+	_, err := Check("github.com/foo/foo", map[string][]byte{"foo_test.go": []byte("package foo\n")})
+	require.Equal(t, ErrNoPackage, err)
+}
@@ -0,0 +1,188 @@
+package apidiff
+
+import (
+	"bytes"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+
+	"github.com/go-fresh/go-fresh/depmap"
+)
+
+// ErrNoPackage is returned when a directory has no Go package.
+var ErrNoPackage = errors.New("no Go package")
+
+// FromTree type-checks the package in the directory of a git tree, dir is relative to the root
+// of the tree and importPath the package's import path. It returns ErrNoPackage when the
+// directory has no Go sources.
+func FromTree(tree *object.Tree, dir, importPath string) (*types.Package, error) {
+	if dir != "" && dir != "." {
+		var err error
+		tree, err = tree.Tree(dir)
+		if err == object.ErrDirectoryNotFound {
+			return nil, ErrNoPackage
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read directory %s", dir)
+		}
+	}
+
+	files := map[string][]byte{}
+	for _, entry := range tree.Entries {
+		if !entry.Mode.IsFile() || !strings.HasSuffix(entry.Name, ".go") {
+			continue
+		}
+		f, err := tree.TreeEntryFile(&entry)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to open %s", entry.Name)
+		}
+		r, err := f.Reader()
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read %s", entry.Name)
+		}
+		src, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read %s", entry.Name)
+		}
+		files[entry.Name] = src
+	}
+	return Check(importPath, files)
+}
+
+// Check type-checks a package from the sources of its files, keyed by file name. Test files and
+// files excluded by build constraints are left out. The package's imports are not loaded, the
+// identifiers used from them stand in for their real declarations, so changes to imported types
+// are only detected by name.
+func Check(importPath string, files map[string][]byte) (*types.Package, error) {
+	ctxt := build.Default
+	ctxt.CgoEnabled = false
+	ctxt.JoinPath = path.Join
+	ctxt.OpenFile = func(name string) (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(files[path.Base(name)])), nil
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fset := token.NewFileSet()
+	parsed := []*ast.File{}
+	for _, name := range names {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		if ok, err := ctxt.MatchFile(".", name); err != nil || !ok {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, files[name], 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse %s", name)
+		}
+		if len(parsed) > 0 && f.Name.Name != parsed[0].Name.Name {
+			// documentation or generator files of another package
+			continue
+		}
+		parsed = append(parsed, f)
+	}
+	if len(parsed) == 0 {
+		return nil, ErrNoPackage
+	}
+
+	conf := types.Config{
+		Importer: newStubImporter(parsed),
+		// imports are stubs, errors in function bodies are expected
+		Error: func(error) {},
+	}
+	pkg, _ := conf.Check(importPath, fset, parsed, nil)
+	return pkg, nil
+}
+
+// stubImporter imports packages declaring only the identifiers the package's files use from them,
+// as named types.
+type stubImporter struct {
+	packages map[string]*types.Package
+}
+
+func newStubImporter(files []*ast.File) *stubImporter {
+	used := map[string]map[string]bool{}
+	names := map[string]string{}
+	for _, f := range files {
+		imports := map[string]string{}
+		aliased := map[string]bool{}
+		for _, spec := range f.Imports {
+			imp, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			if used[imp] == nil {
+				used[imp] = map[string]bool{}
+			}
+			if spec.Name != nil {
+				imports[spec.Name.Name] = imp
+				aliased[spec.Name.Name] = true
+				continue
+			}
+			for _, name := range depmap.ImportNames(imp) {
+				imports[name] = imp
+			}
+		}
+
+		ast.Inspect(f, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			x, ok := sel.X.(*ast.Ident)
+			if !ok || x.Obj != nil {
+				return true
+			}
+			if imp, ok := imports[x.Name]; ok {
+				used[imp][sel.Sel.Name] = true
+				if !aliased[x.Name] {
+					// packages imported without a name are used by their own name
+					names[imp] = x.Name
+				}
+			}
+			return true
+		})
+	}
+
+	s := &stubImporter{packages: map[string]*types.Package{}}
+	for imp, idents := range used {
+		name, ok := names[imp]
+		if !ok {
+			name = depmap.ImportNames(imp)[0]
+		}
+		pkg := types.NewPackage(imp, name)
+		for ident := range idents {
+			tn := types.NewTypeName(token.NoPos, pkg, ident, nil)
+			types.NewNamed(tn, types.NewInterfaceType(nil, nil).Complete(), nil)
+			pkg.Scope().Insert(tn)
+		}
+		pkg.MarkComplete()
+		s.packages[imp] = pkg
+	}
+	return s
+}
+
+func (s *stubImporter) Import(path string) (*types.Package, error) {
+	pkg, ok := s.packages[path]
+	if !ok {
+		return nil, errors.Errorf("package %s is not imported", path)
+	}
+	return pkg, nil
+}
@@ -0,0 +1,185 @@
+// Package changelog collects the release notes between two versions of a dependency.
+package changelog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+
+	"github.com/go-fresh/go-fresh/updater"
+)
+
+// Entry holds the notes of a single version.
+type Entry struct {
+	Version string
+	Date    time.Time
+	URL     string
+
+	// Release is the body of the version's GitHub release.
+	Release string
+
+	// Changelog is the version's section of the repository's changelog file.
+	Changelog string
+}
+
+// Changelog lists the notes of every version of an update, newest first.
+type Changelog struct {
+	Dependency string
+	From, To   string
+	Entries    []Entry
+
+	// Omitted counts the older versions left out by Collector.MaxEntries.
+	Omitted int
+}
+
+// Markdown renders the changelog for a PR description.
+func (c *Changelog) Markdown() string {
+	if c == nil || len(c.Entries) == 0 {
+		return ""
+	}
+
+	var b bytes.Buffer
+	for _, e := range c.Entries {
+		fmt.Fprintf(&b, "## %s\n\n", e.Version)
+		if e.URL != "" {
+			fmt.Fprintf(&b, "%s\n\n", e.URL)
+		}
+
+		release := strings.TrimSpace(e.Release)
+		if release != "" {
+			fmt.Fprintf(&b, "%s\n\n", release)
+		}
+		section := strings.TrimSpace(e.Changelog)
+		if section != "" && section != release {
+			fmt.Fprintf(&b, "%s\n\n", section)
+		}
+	}
+	if c.Omitted > 0 {
+		fmt.Fprintf(&b, "%d older versions are not shown.\n", c.Omitted)
+	}
+	return strings.TrimSpace(b.String()) + "\n"
+}
+
+// changelogFiles are the changelog files looked for in the root of a repository.
+var changelogFiles = []string{
+	"CHANGELOG.md",
+	"CHANGELOG",
+	"CHANGES.md",
+	"HISTORY.md",
+}
+
+// DefaultMaxEntries is the number of versions collected for updates from an unknown version.
+const DefaultMaxEntries = 5
+
+// Collector collects changelogs from a Source.
+type Collector struct {
+	Source Source
+
+	// MaxEntries caps the versions collected when the update's From is not a version, which
+	// would otherwise include every release up to To. Zero collects them all.
+	MaxEntries int
+}
+
+// NewCollector creates a Collector reading from the source.
+func NewCollector(source Source) *Collector {
+	return &Collector{Source: source, MaxEntries: DefaultMaxEntries}
+}
+
+// Collect returns the notes of every version after From up to and including To. Only To's
+// prerelease is included, other prereleases are left out. When From is not a version, only
+// the newest MaxEntries versions are. Updates between revisions, or of dependencies the source
+// does not host, have an empty changelog.
+func (c *Collector) Collect(ctx context.Context, u updater.Update) (*Changelog, error) {
+	cl := &Changelog{
+		Dependency: u.Name,
+		From:       u.From,
+		To:         u.To,
+	}
+
+	owner, repo, ok := c.Source.Repository(u.ProjectRoot)
+	if !ok {
+		owner, repo, ok = c.Source.Repository(u.Name)
+	}
+	if !ok {
+		return cl, nil
+	}
+
+	to, err := semver.NewVersion(u.To)
+	if err != nil {
+		// branch updates have no versions
+		return cl, nil
+	}
+	var from *semver.Version
+	if v, err := semver.NewVersion(u.From); err == nil {
+		from = &v
+	}
+
+	inRange := func(v semver.Version) bool {
+		if v.GreaterThan(to) || (from != nil && !v.GreaterThan(*from)) {
+			return false
+		}
+		return v.Prerelease() == "" || v.Equal(to)
+	}
+
+	// keyed by the normalized version, tags and changelog headings differ in their "v" prefix
+	entries := map[string]*Entry{}
+	versions := semver.Collection{}
+	entry := func(v semver.Version, name string) *Entry {
+		e, ok := entries[v.String()]
+		if !ok {
+			e = &Entry{Version: name}
+			entries[v.String()] = e
+			versions = append(versions, v)
+		}
+		return e
+	}
+
+	releases, err := c.Source.Releases(ctx, owner, repo)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to list releases of %s/%s", owner, repo)
+	}
+	for _, r := range releases {
+		v, err := semver.NewVersion(r.Tag)
+		if err != nil || r.Draft || !inRange(v) {
+			continue
+		}
+		e := entry(v, r.Tag)
+		e.Date = r.Date
+		e.URL = r.URL
+		e.Release = r.Body
+	}
+
+	ref := u.Revision
+	for _, name := range changelogFiles {
+		raw, err := c.Source.File(ctx, owner, repo, name, ref)
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read %s of %s/%s", name, owner, repo)
+		}
+
+		for _, s := range Sections(raw) {
+			if inRange(s.Version) {
+				entry(s.Version, s.Title).Changelog = s.Body
+			}
+		}
+		break
+	}
+
+	sort.Sort(sort.Reverse(versions))
+	if from == nil && c.MaxEntries > 0 && len(versions) > c.MaxEntries {
+		cl.Omitted = len(versions) - c.MaxEntries
+		versions = versions[:c.MaxEntries]
+	}
+	for _, v := range versions {
+		cl.Entries = append(cl.Entries, *entries[v.String()])
+	}
+	return cl, nil
+}
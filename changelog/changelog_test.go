@@ -0,0 +1,117 @@
+package changelog
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fresh/go-fresh/updater"
+)
+
+type fakeSource struct {
+	releases []Release
+	files    map[string]string
+}
+
+func (s *fakeSource) Repository(pkg string) (string, string, bool) {
+	parts := strings.Split(pkg, "/")
+	if len(parts) < 3 || parts[0] != "github.com" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+func (s *fakeSource) Releases(ctx context.Context, owner, repo string) ([]Release, error) {
+	return s.releases, nil
+}
+
+func (s *fakeSource) File(ctx context.Context, owner, repo, path, ref string) ([]byte, error) {
+	raw, ok := s.files[path]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return []byte(raw), nil
+}
+
+func TestCollect(t *testing.T) {
+	assert := require.New(t)
+
+	raw, err := ioutil.ReadFile("testdata/CHANGELOG.md")
+	assert.NoError(err)
+
+	source := &fakeSource{
+		releases: []Release{
+			{Tag: "v1.4.0", Body: "Too new"},
+			{Tag: "v1.3.0", Body: "Contexts everywhere", URL: "https://github.com/foo/bar/releases/tag/v1.3.0"},
+			{Tag: "v1.3.0-rc1", Body: "Release candidate", Prerelease: true},
+			{Tag: "v1.2.2", Body: "Draft", Draft: true},
+			{Tag: "v1.2.0", Body: "Already used"},
+		},
+		files: map[string]string{"CHANGELOG.md": string(raw)},
+	}
+	collector := NewCollector(source)
+
+	cl, err := collector.Collect(context.Background(), updater.Update{
+		Name:        "github.com/foo/bar/pkg",
+		ProjectRoot: "github.com/foo/bar",
+		From:        "1.2.0",
+		To:          "1.3.0",
+	})
+	assert.NoError(err)
+	assert.Equal([]Entry{
+		{
+			Version:   "v1.3.0",
+			URL:       "https://github.com/foo/bar/releases/tag/v1.3.0",
+			Release:   "Contexts everywhere",
+			Changelog: "### Added\n\n- Support for contexts",
+		},
+		{
+			Version:   "1.2.1",
+			Changelog: "### Fixed\n\n- Race in the client",
+		},
+	}, cl.Entries)
+
+	assert.Equal(`## v1.3.0
+
+https://github.com/foo/bar/releases/tag/v1.3.0
+
+Contexts everywhere
+
+### Added
+
+- Support for contexts
+
+## 1.2.1
+
+### Fixed
+
+- Race in the client
+`, cl.Markdown())
+
+	// branch updates and other hosts have no changelog
+	cl, err = collector.Collect(context.Background(), updater.Update{Name: "github.com/foo/bar", From: "abcdef", To: "fedcba", Branch: "master"})
+	assert.NoError(err)
+	assert.Empty(cl.Entries)
+	assert.Equal("", cl.Markdown())
+
+	cl, err = collector.Collect(context.Background(), updater.Update{Name: "example.com/foo", To: "1.3.0"})
+	assert.NoError(err)
+	assert.Empty(cl.Entries)
+
+	// without a current version only the newest entries are kept
+	collector.MaxEntries = 2
+	cl, err = collector.Collect(context.Background(), updater.Update{
+		Name:        "github.com/foo/bar",
+		ProjectRoot: "github.com/foo/bar",
+		From:        "abcdef",
+		To:          "1.3.0",
+	})
+	assert.NoError(err)
+	assert.Len(cl.Entries, 2)
+	assert.Equal("v1.3.0", cl.Entries[0].Version)
+	assert.Equal(1, cl.Omitted)
+	assert.Contains(cl.Markdown(), "older versions are not shown")
+}
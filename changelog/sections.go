@@ -0,0 +1,74 @@
+package changelog
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+// Section is the part of a changelog file describing a single version.
+type Section struct {
+	Version semver.Version
+	Title   string
+	Body    string
+}
+
+var headingVersion = regexp.MustCompile(`v?\d+\.\d+(\.\d+)?(-[0-9A-Za-z.-]+)?`)
+
+// Sections splits a markdown changelog into its version sections. A section starts at a heading
+// containing a version, such as "## [1.2.0] - 2018-05-01", and ends at the next heading of the
+// same or a higher level.
+func Sections(raw []byte) []Section {
+	sections := []Section{}
+
+	var (
+		current *Section
+		level   int
+		body    []string
+	)
+	finish := func() {
+		if current != nil {
+			current.Body = strings.TrimSpace(strings.Join(body, "\n"))
+			sections = append(sections, *current)
+		}
+		current, body = nil, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if l := headingLevel(line); l > 0 && (current == nil || l <= level) {
+			finish()
+
+			name := headingVersion.FindString(line[l:])
+			if v, err := semver.NewVersion(name); err == nil {
+				current = &Section{Version: v, Title: name}
+				level = l
+			}
+			continue
+		}
+
+		if current != nil {
+			body = append(body, line)
+		}
+	}
+	finish()
+
+	return sections
+}
+
+// headingLevel returns the level of an ATX heading, or zero if the line is not a heading.
+func headingLevel(line string) int {
+	l := 0
+	for l < len(line) && line[l] == '#' {
+		l++
+	}
+	if l == 0 || l > 6 || (l < len(line) && line[l] != ' ') {
+		return 0
+	}
+	return l
+}
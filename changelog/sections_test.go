@@ -0,0 +1,41 @@
+package changelog
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSections(t *testing.T) {
+	assert := require.New(t)
+
+	raw, err := ioutil.ReadFile("testdata/CHANGELOG.md")
+	assert.NoError(err)
+
+	sections := Sections(raw)
+
+	titles := []string{}
+	for _, s := range sections {
+		titles = append(titles, s.Title)
+	}
+	assert.Equal([]string{"1.3.0", "1.2.1", "v1.2.0", "1.1.0-rc1"}, titles)
+	assert.Equal("### Added\n\n- Support for contexts", sections[0].Body)
+	assert.Equal("- Initial release of the client", sections[2].Body)
+}
+
+func TestHeadingLevel(t *testing.T) {
+	for line, expected := range map[string]int{
+		"# Changelog":  1,
+		"## 1.2.0":     2,
+		"###### 1.2.0": 6,
+		"####### deep": 0,
+		"#hashtag":     0,
+		"text":         0,
+		"":             0,
+	} {
+		t.Run(line, func(t *testing.T) {
+			require.Equal(t, expected, headingLevel(line))
+		})
+	}
+}
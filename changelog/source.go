@@ -0,0 +1,101 @@
+package changelog
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// ErrNotFound is returned by a Source for files that do not exist.
+var ErrNotFound = errors.New("not found")
+
+// Release is a published release of a repository.
+type Release struct {
+	Tag  string
+	Body string
+	URL  string
+	Date time.Time
+
+	Draft      bool
+	Prerelease bool
+}
+
+// Source hosts the releases and files of repositories.
+type Source interface {
+	// Repository returns the owner and name of the repository of a package, if the source hosts it.
+	Repository(pkg string) (owner, repo string, ok bool)
+
+	// Releases returns all the releases of the repository.
+	Releases(ctx context.Context, owner, repo string) ([]Release, error)
+
+	// File returns the contents of the file at the ref, an empty ref is the default branch.
+	File(ctx context.Context, owner, repo, path, ref string) ([]byte, error)
+}
+
+type githubSource struct {
+	client *github.Client
+}
+
+// NewGithubSource creates a Source for repositories hosted on GitHub.
+func NewGithubSource(client *github.Client) Source {
+	return &githubSource{client: client}
+}
+
+func (s *githubSource) Repository(pkg string) (string, string, bool) {
+	parts := strings.Split(pkg, "/")
+	if len(parts) < 3 || parts[0] != "github.com" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+func (s *githubSource) Releases(ctx context.Context, owner, repo string) ([]Release, error) {
+	releases := []Release{}
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := s.client.Repositories.ListReleases(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range page {
+			releases = append(releases, Release{
+				Tag:        r.GetTagName(),
+				Body:       r.GetBody(),
+				URL:        r.GetHTMLURL(),
+				Date:       r.GetPublishedAt().Time,
+				Draft:      r.GetDraft(),
+				Prerelease: r.GetPrerelease(),
+			})
+		}
+		if resp.NextPage == 0 {
+			return releases, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func (s *githubSource) File(ctx context.Context, owner, repo, path, ref string) ([]byte, error) {
+	file, _, resp, err := s.client.Repositories.GetContents(ctx, owner, repo, path, &github.RepositoryContentGetOptions{
+		Ref: ref,
+	})
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		// a directory
+		return nil, ErrNotFound
+	}
+
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to decode %s", path)
+	}
+	return []byte(content), nil
+}
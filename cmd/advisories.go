@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-fresh/go-fresh/vuln"
+)
+
+type advisoriesCommand struct {
+}
+
+func (c advisoriesCommand) Flags(m *meta) error {
+	m.Flags.String("advisories", "", "path to an OSV advisory database directory, .zip or .tar.gz archive")
+
+	return nil
+}
+
+// Advisories loads the advisory database, it returns nil when none is configured.
+func (c advisoriesCommand) Advisories(ctx context.Context) (*vuln.Database, error) {
+	path, err := flags(ctx).GetString("advisories")
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return nil, nil
+	}
+
+	db, err := vuln.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ui(ctx).Info(fmt.Sprintf("loaded %d advisories from %q", len(db.Advisories), path))
+	return db, nil
+}
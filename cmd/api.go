@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/go-fresh/go-fresh/apidiff"
+	"github.com/go-fresh/go-fresh/updater"
+)
+
+type apiCommand struct {
+}
+
+func (c apiCommand) Flags(m *meta) error {
+	m.Flags.Bool("compare-api", false, "report breaking changes to the dependency API projects refer to")
+
+	return nil
+}
+
+// APIAnalyzer returns the API analyzer, reading the API from history. It returns nil when
+// API changes are not compared.
+func (c apiCommand) APIAnalyzer(ctx context.Context, history *updater.GitHistory) (updater.APIAnalyzer, error) {
+	compare, err := flags(ctx).GetBool("compare-api")
+	if err != nil {
+		return nil, err
+	}
+	if !compare {
+		return nil, nil
+	}
+	return history, nil
+}
+
+// releaseAPI compares the API of a released repository, remembering the changes, or the error,
+// of each comparison so projects referring to the same packages from the same revision do not
+// type-check them again.
+type releaseAPI struct {
+	api     updater.APIAnalyzer
+	changes map[string][]apidiff.Change
+	errs    map[string]error
+}
+
+func newReleaseAPI(api updater.APIAnalyzer) *releaseAPI {
+	return &releaseAPI{
+		api:     api,
+		changes: map[string][]apidiff.Change{},
+		errs:    map[string]error{},
+	}
+}
+
+// CompareAPI implements updater.APIAnalyzer.
+func (r *releaseAPI) CompareAPI(ctx context.Context, gitURL, branch, from, to string, pkgs map[string]string) ([]apidiff.Change, error) {
+	key := []string{gitURL, from, to}
+	for imp, dir := range pkgs {
+		key = append(key, imp+"="+dir)
+	}
+	sort.Strings(key[3:])
+	k := strings.Join(key, "\x00")
+
+	if err, ok := r.errs[k]; ok {
+		return nil, err
+	}
+	if changes, ok := r.changes[k]; ok {
+		return changes, nil
+	}
+
+	changes, err := r.api.CompareAPI(ctx, gitURL, branch, from, to, pkgs)
+	if err != nil {
+		r.errs[k] = err
+		return nil, err
+	}
+	r.changes[k] = changes
+	return changes, nil
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fresh/go-fresh/apidiff"
+)
+
+type countingAPIAnalyzer struct {
+	calls int
+}
+
+func (a *countingAPIAnalyzer) CompareAPI(ctx context.Context, gitURL, branch, from, to string, pkgs map[string]string) ([]apidiff.Change, error) {
+	a.calls++
+	return []apidiff.Change{{Package: "github.com/foo/bar", Name: "Do", Kind: apidiff.Removed}}, nil
+}
+
+func TestReleaseAPI(t *testing.T) {
+	assert := require.New(t)
+
+	counting := &countingAPIAnalyzer{}
+	api := newReleaseAPI(counting)
+	ctx := context.Background()
+
+	pkgs := map[string]string{"github.com/foo/bar": ".", "github.com/foo/bar/baz": "baz"}
+	for i := 0; i < 2; i++ {
+		changes, err := api.CompareAPI(ctx, "https://github.com/foo/bar.git", "master", "v1.0.0", "v1.1.0", pkgs)
+		assert.NoError(err)
+		assert.Len(changes, 1)
+	}
+	assert.Equal(1, counting.calls)
+
+	// other packages or revisions are compared again
+	_, err := api.CompareAPI(ctx, "https://github.com/foo/bar.git", "master", "v1.0.0", "v1.1.0", map[string]string{"github.com/foo/bar": "."})
+	assert.NoError(err)
+	_, err = api.CompareAPI(ctx, "https://github.com/foo/bar.git", "master", "v0.9.0", "v1.1.0", pkgs)
+	assert.NoError(err)
+	assert.Equal(3, counting.calls)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/go-fresh/go-fresh/depmap"
+)
+
+type cacheCommand struct {
+}
+
+func (c cacheCommand) Flags(m *meta) error {
+	m.Flags.String("cache-dir", "", "directory to cache cloned repositories and sources in, disabled if empty")
+	m.Flags.Int64("cache-max-size", 2048, "maximum size of the repository cache in MB, 0 for no limit")
+	m.Flags.Duration("cache-max-age", 7*24*time.Hour, "remove cached repositories unused for this long, 0 to keep forever")
+
+	return nil
+}
+
+// CacheDir returns the absolute cache directory, or an empty string if caching is disabled.
+func (c cacheCommand) CacheDir(ctx context.Context) (string, error) {
+	dir, err := flags(ctx).GetString("cache-dir")
+	if err != nil {
+		return "", err
+	}
+	if dir == "" {
+		return "", nil
+	}
+	return filepath.Abs(dir)
+}
+
+// Cache returns the repository cache, or nil if caching is disabled.
+func (c cacheCommand) Cache(ctx context.Context) (*depmap.Cache, error) {
+	dir, err := c.CacheDir(ctx)
+	if err != nil || dir == "" {
+		return nil, err
+	}
+	maxSize, err := flags(ctx).GetInt64("cache-max-size")
+	if err != nil {
+		return nil, err
+	}
+	maxAge, err := flags(ctx).GetDuration("cache-max-age")
+	if err != nil {
+		return nil, err
+	}
+
+	ui(ctx).Info(fmt.Sprintf("using repository cache %q", dir))
+	return depmap.NewCache(filepath.Join(dir, "repos"), maxSize*1024*1024, maxAge)
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/mitchellh/cli"
+	"github.com/pkg/errors"
+
+	"github.com/go-fresh/go-fresh/changelog"
+	"github.com/go-fresh/go-fresh/updater"
+)
+
+type changelogShowCommand struct {
+	githubCommand
+}
+
+// ChangelogShowCommandFactory creates the "changelog show" command
+func ChangelogShowCommandFactory(ui cli.Ui) cli.CommandFactory {
+	cmd := &changelogShowCommand{}
+	return newCommandFactory(ui, "changelog show", cmd, func(m *meta) error {
+		m.Synopsis = "prints the release notes of a dependency between two versions"
+
+		m.Flags.StringP("dependency", "d", "", "dependency to show release notes for")
+		m.Flags.StringP("from-version", "f", "", "version updated from, excluded from the notes")
+		m.Flags.String("to-version", "", "version updated to")
+
+		return m.Register(
+			cmd.githubCommand,
+		)
+	})
+}
+
+func (c *changelogShowCommand) Run(ctx context.Context) error {
+	dependency, err := flags(ctx).GetString("dependency")
+	if err != nil {
+		return err
+	}
+	if dependency == "" {
+		return errors.Errorf("dependency is required")
+	}
+	fromversion, err := flags(ctx).GetString("from-version")
+	if err != nil {
+		return err
+	}
+	toversion, err := flags(ctx).GetString("to-version")
+	if err != nil {
+		return err
+	}
+	if toversion == "" {
+		return errors.Errorf("to-version is required")
+	}
+
+	client, err := c.GithubClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	notes := changelog.NewCollector(changelog.NewGithubSource(client))
+	cl, err := notes.Collect(ctx, updater.Update{
+		Name: dependency,
+		From: fromversion,
+		To:   toversion,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(cl.Entries) == 0 {
+		ui(ctx).Info("no release notes found")
+		return nil
+	}
+	ui(ctx).Output(cl.Markdown())
+	return nil
+}
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+
+	"github.com/go-fresh/go-fresh/data"
+	"github.com/go-fresh/go-fresh/depmap"
+	"github.com/go-fresh/go-fresh/updater"
+)
+
+type cooldownCommand struct {
+}
+
+func (c cooldownCommand) Flags(m *meta) error {
+	m.Flags.Duration("min-release-age", 0, "minimum age of releases before PRs are submitted, zero submits them right away")
+	m.Flags.Duration("pending-interval", time.Minute, "interval to submit pending updates whose release has aged")
+	m.Flags.Duration("group-window", 0, "time a project's pending updates wait for releases of other dependencies to group into the same PRs, zero submits them once due")
+
+	return nil
+}
+
+// cooldown configures how long updates are held in the pending queue.
+type cooldown struct {
+	// minReleaseAge holds updates until their release is this old.
+	minReleaseAge time.Duration
+
+	// interval is how often pending updates are submitted.
+	interval time.Duration
+
+	// groupWindow holds a project's due updates until the first of them has been due this
+	// long, so releases of different dependencies are grouped together.
+	groupWindow time.Duration
+}
+
+// Cooldown returns the minimum release age, the interval to check pending updates at and the
+// window to group them in.
+func (c cooldownCommand) Cooldown(ctx context.Context) (cooldown, error) {
+	age, err := flags(ctx).GetDuration("min-release-age")
+	if err != nil {
+		return cooldown{}, err
+	}
+	interval, err := flags(ctx).GetDuration("pending-interval")
+	if err != nil {
+		return cooldown{}, err
+	}
+	if interval <= 0 {
+		return cooldown{}, errors.Errorf("invalid pending-interval %v, it must be positive", interval)
+	}
+	window, err := flags(ctx).GetDuration("group-window")
+	if err != nil {
+		return cooldown{}, err
+	}
+	if window < 0 {
+		return cooldown{}, errors.Errorf("invalid group-window %v, it must not be negative", window)
+	}
+	if age > 0 {
+		ui(ctx).Info(fmt.Sprintf("holding releases until they are %v old", age))
+	}
+	if window > 0 {
+		ui(ctx).Info(fmt.Sprintf("grouping releases within %v of each other", window))
+	}
+	return cooldown{minReleaseAge: age, interval: interval, groupWindow: window}, nil
+}
+
+// pendingUpdate returns the pending update of a project's update, due at due.
+func pendingUpdate(project string, u updater.Update, due time.Time) (data.PendingUpdate, error) {
+	encoded, err := json.Marshal(u)
+	if err != nil {
+		return data.PendingUpdate{}, err
+	}
+	return data.PendingUpdate{Project: project, Name: u.Name, Manifest: u.Manifest, To: u.To, Update: encoded, Due: due}, nil
+}
+
+// schedulePendingUpdates submits pending updates every interval until the context is done.
+func schedulePendingUpdates(ctx context.Context, db data.Client, submitter updater.Submitter, c cooldown) {
+	tick := time.NewTicker(c.interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-tick.C:
+			err := submitPendingUpdates(ctx, db, submitter, now, c.groupWindow)
+			if err != nil {
+				ui(ctx).Error(fmt.Sprintf("error submitting pending updates: %s", err))
+			}
+		}
+	}
+}
+
+// submitPendingUpdates submits the pending updates due at now, grouped by each project's
+// configuration. Updates the project no longer needs, because it already depends on the
+// version or newer, or no longer allows, are dropped. A project's due updates are held until
+// the first of them has been due for window, to be grouped with the releases due meanwhile.
+func submitPendingUpdates(ctx context.Context, db data.Client, submitter updater.Submitter, now time.Time, window time.Duration) error {
+	pending, err := db.PendingUpdates()
+	if err != nil {
+		return err
+	}
+
+	due := map[string][]data.PendingUpdate{}
+	first := map[string]time.Time{}
+	projects := []string{}
+	for _, p := range pending {
+		if p.Due.After(now) {
+			continue
+		}
+		if _, ok := due[p.Project]; !ok {
+			projects = append(projects, p.Project)
+		}
+		due[p.Project] = append(due[p.Project], p)
+		if f, ok := first[p.Project]; !ok || p.Due.Before(f) {
+			first[p.Project] = p.Due
+		}
+	}
+
+	for _, name := range projects {
+		if first[name].Add(window).After(now) {
+			// still waiting for other releases to group with
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		project, deps, err := db.Project(name)
+		if err != nil && err != data.ErrNotFound {
+			return err
+		}
+
+		updates := []updater.Update{}
+		for _, p := range due[name] {
+			var u updater.Update
+			decodeErr := json.Unmarshal(p.Update, &u)
+			if err == data.ErrNotFound || decodeErr != nil || superseded(deps, u) || !updater.Allowed(project.Config, u) {
+				ui(ctx).Info(fmt.Sprintf("dropping pending update of %s (%s) to %s %s", name, p.Manifest, p.Name, p.To))
+				if err := db.RemovePendingUpdate(p); err != nil {
+					return err
+				}
+				continue
+			}
+			updates = append(updates, u)
+		}
+		if len(updates) == 0 {
+			continue
+		}
+
+		batches, err := updater.Group(project.Config, updates)
+		if err != nil {
+			// an invalid grouping rule should not hold back the updates
+			ui(ctx).Warn(fmt.Sprintf("unable to group updates for %s: %s", name, err))
+			batches, _ = updater.Group(nil, updates)
+		}
+		for _, batch := range batches {
+			err = submitter.SubmitBatch(ctx, project, batch)
+			if err != nil {
+				return err
+			}
+			ui(ctx).Info("PR submitted")
+
+			for _, u := range batch.Updates {
+				err = db.RemovePendingUpdate(data.PendingUpdate{Project: name, Name: u.Name, Manifest: u.Manifest, To: u.To})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// superseded reports if the manifest of the update already depends on its version, or a newer one.
+func superseded(deps []depmap.Dependency, u updater.Update) bool {
+	to, err := semver.NewVersion(u.To)
+	if err != nil {
+		return false
+	}
+
+	key := strings.ToLower(u.Name)
+	for _, d := range deps {
+		name := strings.ToLower(d.Name)
+		if name != key && !strings.HasPrefix(name, key+"/") {
+			continue
+		}
+		manifest := d.Manifest
+		if manifest == "" {
+			manifest = "."
+		}
+		if manifest != u.Manifest {
+			continue
+		}
+
+		current, err := semver.NewVersion(d.Version)
+		if err == nil && !current.LessThan(to) {
+			return true
+		}
+	}
+	return false
+}
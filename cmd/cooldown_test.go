@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/mitchellh/cli"
+	flag "github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fresh/go-fresh/data"
+	"github.com/go-fresh/go-fresh/depmap"
+	"github.com/go-fresh/go-fresh/updater"
+)
+
+func TestCooldown(t *testing.T) {
+	for i, c := range []struct {
+		args  []string
+		valid bool
+	}{
+		{[]string{}, true},
+		{[]string{"--min-release-age", "24h", "--pending-interval", "10m"}, true},
+		{[]string{"--pending-interval", "0s"}, false},
+		{[]string{"--pending-interval", "-1m"}, false},
+		{[]string{"--group-window", "1h"}, true},
+		{[]string{"--group-window", "-1h"}, false},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert := require.New(t)
+
+			m := &meta{Flags: flag.NewFlagSet("cooldown", flag.ContinueOnError)}
+			assert.NoError(cooldownCommand{}.Flags(m))
+			assert.NoError(m.Flags.Parse(c.args))
+
+			ctx := context.WithValue(context.Background(), contextKeyUI, cli.Ui(&cli.MockUi{}))
+			ctx = context.WithValue(ctx, contextKeyFlags, m.Flags)
+			cooldown, err := cooldownCommand{}.Cooldown(ctx)
+			if !c.valid {
+				assert.Error(err)
+				return
+			}
+			assert.NoError(err)
+			assert.True(cooldown.interval > 0)
+		})
+	}
+}
+
+func TestSuperseded(t *testing.T) {
+	deps := []depmap.Dependency{
+		{Name: "github.com/foo/bar/pkg", Manifest: ".", Version: "v1.2.0"},
+		{Name: "github.com/foo/baz", Manifest: "tools", Version: "v1.0.0"},
+		{Name: "github.com/foo/qux"},
+	}
+
+	for i, c := range []struct {
+		expected bool
+		update   updater.Update
+	}{
+		{true, updater.Update{Name: "github.com/foo/bar", Manifest: ".", To: "1.1.0"}},
+		{true, updater.Update{Name: "github.com/foo/bar", Manifest: ".", To: "1.2.0"}},
+		{false, updater.Update{Name: "github.com/foo/bar", Manifest: ".", To: "1.2.1"}},
+		{false, updater.Update{Name: "github.com/foo/bar", Manifest: "tools", To: "1.1.0"}},
+		{false, updater.Update{Name: "github.com/foo/baz", Manifest: "tools", To: "1.1.0"}},
+		{false, updater.Update{Name: "github.com/foo/qux", Manifest: ".", To: "1.1.0"}},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert.Equal(t, c.expected, superseded(deps, c.update))
+		})
+	}
+}
+
+type recordingSubmitter struct {
+	batches []updater.Batch
+}
+
+func (s *recordingSubmitter) SubmitPR(ctx context.Context, project depmap.Project, u updater.Update) error {
+	return s.SubmitBatch(ctx, project, updater.Batch{Name: u.Name, Updates: []updater.Update{u}})
+}
+
+func (s *recordingSubmitter) SubmitBatch(ctx context.Context, project depmap.Project, batch updater.Batch) error {
+	s.batches = append(s.batches, batch)
+	return nil
+}
+
+func TestSubmitPendingUpdates(t *testing.T) {
+	assert := require.New(t)
+
+	tmp, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+	bdb, err := bolt.Open(filepath.Join(tmp, "bolt.db"), 0644, nil)
+	assert.NoError(err)
+	defer bdb.Close()
+	db := data.NewBoltClient(bdb)
+
+	project := depmap.Project{Name: "example.com/foo/project", Config: &depmap.Config{Group: updater.GroupAll}}
+	assert.NoError(db.RegisterProject(project, []depmap.Dependency{
+		{Name: "github.com/foo/bar", Manifest: ".", Version: "v1.0.0"},
+		{Name: "github.com/foo/baz", Manifest: ".", Version: "v1.0.0"},
+		{Name: "github.com/foo/qux", Manifest: ".", Version: "v1.5.0"},
+		{Name: "github.com/foo/later", Manifest: ".", Version: "v1.0.0"},
+	}))
+
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, p := range []struct {
+		project string
+		update  updater.Update
+		due     time.Time
+	}{
+		{project.Name, updater.Update{Name: "github.com/foo/bar", Manifest: ".", From: "1.0.0", To: "1.0.1"}, now.Add(-time.Hour)},
+		{project.Name, updater.Update{Name: "github.com/foo/baz", Manifest: ".", From: "1.0.0", To: "1.1.0"}, now},
+		{project.Name, updater.Update{Name: "github.com/foo/qux", Manifest: ".", From: "1.0.0", To: "1.1.0"}, now},
+		{project.Name, updater.Update{Name: "github.com/foo/later", Manifest: ".", From: "1.0.0", To: "1.1.0"}, now.Add(time.Hour)},
+		{"example.com/foo/unregistered", updater.Update{Name: "github.com/foo/bar", Manifest: ".", To: "1.0.1"}, now},
+	} {
+		pending, err := pendingUpdate(p.project, p.update, p.due)
+		assert.NoError(err)
+		_, err = db.QueueUpdate(pending)
+		assert.NoError(err)
+	}
+
+	ctx := context.WithValue(context.Background(), contextKeyUI, cli.Ui(&cli.MockUi{}))
+	submitter := &recordingSubmitter{}
+	assert.NoError(submitPendingUpdates(ctx, db, submitter, now, 0))
+
+	assert.Len(submitter.batches, 1)
+	submitted := []string{}
+	for _, u := range submitter.batches[0].Updates {
+		submitted = append(submitted, u.Name)
+	}
+	assert.ElementsMatch([]string{"github.com/foo/bar", "github.com/foo/baz"}, submitted)
+
+	pending, err := db.PendingUpdates()
+	assert.NoError(err)
+	assert.Len(pending, 1)
+	assert.Equal("github.com/foo/later", pending[0].Name)
+}
+
+func TestSubmitPendingUpdates_GroupWindow(t *testing.T) {
+	assert := require.New(t)
+
+	tmp, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+	bdb, err := bolt.Open(filepath.Join(tmp, "bolt.db"), 0644, nil)
+	assert.NoError(err)
+	defer bdb.Close()
+	db := data.NewBoltClient(bdb)
+
+	project := depmap.Project{Name: "example.com/foo/project", Config: &depmap.Config{Group: updater.GroupAll}}
+	assert.NoError(db.RegisterProject(project, []depmap.Dependency{
+		{Name: "github.com/foo/bar", Manifest: ".", Version: "v1.0.0"},
+		{Name: "github.com/foo/baz", Manifest: ".", Version: "v1.0.0"},
+	}))
+
+	// releases of different dependencies, ten minutes apart
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, p := range []struct {
+		update updater.Update
+		due    time.Time
+	}{
+		{updater.Update{Name: "github.com/foo/bar", Manifest: ".", From: "1.0.0", To: "1.0.1"}, now},
+		{updater.Update{Name: "github.com/foo/baz", Manifest: ".", From: "1.0.0", To: "1.1.0"}, now.Add(10 * time.Minute)},
+	} {
+		pending, err := pendingUpdate(project.Name, p.update, p.due)
+		assert.NoError(err)
+		_, err = db.QueueUpdate(pending)
+		assert.NoError(err)
+	}
+
+	ctx := context.WithValue(context.Background(), contextKeyUI, cli.Ui(&cli.MockUi{}))
+	submitter := &recordingSubmitter{}
+
+	// held while the window of the first due update is open
+	for _, at := range []time.Duration{0, 5 * time.Minute, 20 * time.Minute} {
+		assert.NoError(submitPendingUpdates(ctx, db, submitter, now.Add(at), 30*time.Minute))
+		assert.Empty(submitter.batches)
+	}
+
+	assert.NoError(submitPendingUpdates(ctx, db, submitter, now.Add(30*time.Minute), 30*time.Minute))
+	assert.Len(submitter.batches, 1)
+	submitted := []string{}
+	for _, u := range submitter.batches[0].Updates {
+		submitted = append(submitted, u.Name)
+	}
+	assert.ElementsMatch([]string{"github.com/foo/bar", "github.com/foo/baz"}, submitted)
+
+	pending, err := db.PendingUpdates()
+	assert.NoError(err)
+	assert.Empty(pending)
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-fresh/go-fresh/depmap"
+)
+
+// credentialsCommand reuses the token of githubCommand when it is registered.
+type credentialsCommand struct {
+}
+
+func (c credentialsCommand) Flags(m *meta) error {
+	m.Flags.String("credentials-file", "", "HCL file with per host Git credentials")
+
+	return nil
+}
+
+func (c credentialsCommand) Credentials(ctx context.Context) (*depmap.Credentials, error) {
+	path, err := flags(ctx).GetString("credentials-file")
+	if err != nil {
+		return nil, err
+	}
+
+	creds := &depmap.Credentials{}
+	if path != "" {
+		ui(ctx).Info(fmt.Sprintf("using credentials file %q", path))
+		creds, err = depmap.LoadCredentials(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if flags(ctx).Lookup("github-token") != nil {
+		creds.GithubToken, err = flags(ctx).GetString("github-token")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return creds, nil
+}
@@ -14,9 +14,17 @@ import (
 
 type githubListenCommand struct {
 	boltCommand
+	cacheCommand
+	credentialsCommand
+	advisoriesCommand
 	submitterCommand
+	licenseCommand
+	apiCommand
+	cooldownCommand
+	policyCommand
 
 	submitter updater.Submitter
+	opts      releaseOptions
 	db        data.Client
 	secretKey []byte
 	ui        cli.Ui
@@ -35,7 +43,14 @@ func GithubListenCommandFactory(ui cli.Ui) cli.CommandFactory {
 
 		return m.Register(
 			cmd.boltCommand,
+			cmd.cacheCommand,
+			cmd.credentialsCommand,
+			cmd.advisoriesCommand,
 			cmd.submitterCommand,
+			cmd.licenseCommand,
+			cmd.apiCommand,
+			cmd.cooldownCommand,
+			cmd.policyCommand,
 		)
 	})
 }
@@ -64,6 +79,40 @@ func (c *githubListenCommand) Run(ctx context.Context) error {
 		return err
 	}
 
+	cache, err := c.Cache(ctx)
+	if err != nil {
+		return err
+	}
+	creds, err := c.Credentials(ctx)
+	if err != nil {
+		return err
+	}
+	// license detection and API comparison share the clones of released repositories
+	history := updater.NewGitHistory(cache, creds)
+	c.opts.licenses, c.opts.detectLicenses, c.opts.denyLicenses, err = c.LicenseDetector(ctx, history)
+	if err != nil {
+		return err
+	}
+	c.opts.api, err = c.APIAnalyzer(ctx, history)
+	if err != nil {
+		return err
+	}
+	c.opts.advisories, err = c.Advisories(ctx)
+	if err != nil {
+		return err
+	}
+	c.opts.policy, err = c.Policy(ctx)
+	if err != nil {
+		return err
+	}
+	c.opts.ancestry = history.Ancestry(ctx, 0)
+	cooldown, err := c.Cooldown(ctx)
+	if err != nil {
+		return err
+	}
+	c.opts.minReleaseAge, c.opts.groupWindow = cooldown.minReleaseAge, cooldown.groupWindow
+	go schedulePendingUpdates(ctx, c.db, c.submitter, cooldown)
+
 	return http.ListenAndServe(bind, http.HandlerFunc(c.handleWebhook))
 }
 
@@ -90,7 +139,7 @@ func (c *githubListenCommand) handleWebhook(w http.ResponseWriter, r *http.Reque
 
 	switch event := event.(type) {
 	case *github.ReleaseEvent:
-		err = processReleaseEvent(ctx, c.db, c.submitter, event)
+		err = processReleaseEvent(ctx, c.db, c.submitter, c.opts, event)
 		if err != nil {
 			c.handlerError(w, err)
 			return
@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/semver"
@@ -11,14 +12,25 @@ import (
 	"github.com/mitchellh/cli"
 	"github.com/pkg/errors"
 
+	"github.com/go-fresh/go-fresh/changelog"
 	"github.com/go-fresh/go-fresh/data"
+	"github.com/go-fresh/go-fresh/depmap"
+	"github.com/go-fresh/go-fresh/license"
 	"github.com/go-fresh/go-fresh/updater"
+	"github.com/go-fresh/go-fresh/vuln"
 )
 
 type githubWatchCommand struct {
 	githubCommand
 	boltCommand
+	cacheCommand
+	credentialsCommand
+	advisoriesCommand
 	submitterCommand
+	licenseCommand
+	apiCommand
+	cooldownCommand
+	policyCommand
 
 	db data.Client
 }
@@ -37,7 +49,14 @@ func GithubWatchCommandFactory(ui cli.Ui) cli.CommandFactory {
 		return m.Register(
 			cmd.githubCommand,
 			cmd.boltCommand,
+			cmd.cacheCommand,
+			cmd.credentialsCommand,
+			cmd.advisoriesCommand,
 			cmd.submitterCommand,
+			cmd.licenseCommand,
+			cmd.apiCommand,
+			cmd.cooldownCommand,
+			cmd.policyCommand,
 		)
 	})
 }
@@ -68,6 +87,42 @@ func (c *githubWatchCommand) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	opts := releaseOptions{
+		notes: changelog.NewCollector(changelog.NewGithubSource(client)),
+	}
+	cache, err := c.Cache(ctx)
+	if err != nil {
+		return err
+	}
+	creds, err := c.Credentials(ctx)
+	if err != nil {
+		return err
+	}
+	// license detection and API comparison share the clones of released repositories
+	history := updater.NewGitHistory(cache, creds)
+	opts.licenses, opts.detectLicenses, opts.denyLicenses, err = c.LicenseDetector(ctx, history)
+	if err != nil {
+		return err
+	}
+	opts.api, err = c.APIAnalyzer(ctx, history)
+	if err != nil {
+		return err
+	}
+	opts.advisories, err = c.Advisories(ctx)
+	if err != nil {
+		return err
+	}
+	opts.policy, err = c.Policy(ctx)
+	if err != nil {
+		return err
+	}
+	opts.ancestry = history.Ancestry(ctx, 0)
+	cooldown, err := c.Cooldown(ctx)
+	if err != nil {
+		return err
+	}
+	opts.minReleaseAge, opts.groupWindow = cooldown.minReleaseAge, cooldown.groupWindow
+	go schedulePendingUpdates(ctx, c.db, submitter, cooldown)
 
 	// TODO: i imagine this will eventually grow too large, should this eject?
 	// should it be an inverse bloom or something?
@@ -151,7 +206,7 @@ func (c *githubWatchCommand) Run(ctx context.Context) error {
 				ui.Warn("not fast enough!")
 			}
 
-			go func() { processingErrors <- processEvents(ctx, c.db, submitter, newEvents) }()
+			go func() { processingErrors <- processEvents(ctx, c.db, submitter, opts, newEvents) }()
 
 			// record observed keys, this assumes successful processing which may not be the case
 			// do not persist this variable as its not entirely accurate outside of the singleton
@@ -163,7 +218,40 @@ func (c *githubWatchCommand) Run(ctx context.Context) error {
 	}
 }
 
-func processEvents(ctx context.Context, db data.Client, submitter updater.Submitter, events []*github.Event) error {
+// releaseOptions configures how release events are processed.
+type releaseOptions struct {
+	// notes collects the release notes of PRs, they are taken from the release itself when nil.
+	notes *changelog.Collector
+
+	// licenses detects the licenses of released dependencies, for every project when
+	// detectLicenses is set and otherwise for projects denying licenses. They are not
+	// detected when nil.
+	licenses       updater.LicenseDetector
+	detectLicenses bool
+
+	// denyLicenses blocks PRs updating dependencies to these licenses, in addition to the
+	// licenses denied by each project's configuration.
+	denyLicenses []string
+
+	// api compares the API of released dependencies, it is not compared when nil.
+	api updater.APIAnalyzer
+
+	// minReleaseAge holds updates in the pending queue until their release is this old.
+	// groupWindow queues every update, to group it with releases of other dependencies.
+	minReleaseAge time.Duration
+	groupWindow   time.Duration
+
+	// advisories mark updates fixing vulnerabilities as security updates, which are not held
+	// back by update policies or the minimum release age. ancestry matches dependencies locked
+	// to a commit to the advisories, it may be nil.
+	advisories *vuln.Database
+	ancestry   vuln.Ancestry
+
+	// policy is the update policy of dependencies without one in their project's config.
+	policy updater.Policy
+}
+
+func processEvents(ctx context.Context, db data.Client, submitter updater.Submitter, opts releaseOptions, events []*github.Event) error {
 	for _, e := range events {
 		select {
 		case <-ctx.Done():
@@ -186,7 +274,7 @@ func processEvents(ctx context.Context, db data.Client, submitter updater.Submit
 			// promote repo from event to payload
 			re.Repo = e.Repo
 
-			err = processReleaseEvent(ctx, db, submitter, re)
+			err = processReleaseEvent(ctx, db, submitter, opts, re)
 			if err != nil {
 				return err
 			}
@@ -201,13 +289,8 @@ func shouldIgnoreReleaseEvent(event *github.ReleaseEvent) bool {
 		return true
 	}
 
-	if event.Release.GetPrerelease() {
-		// skipping %s, release event is flagged pre-release", repoName)
-		return true
-	}
-
 	tagName := event.Release.GetTagName()
-	v, err := semver.NewVersion(tagName)
+	_, err := semver.NewVersion(tagName)
 	if err == semver.ErrInvalidSemVer {
 		// kipping %s, release event tag is not valid semver: %q", repoName, tagName)
 		return true
@@ -218,15 +301,16 @@ func shouldIgnoreReleaseEvent(event *github.ReleaseEvent) bool {
 		return true
 	}
 
-	if v.Prerelease() != "" {
-		// skipping %s, release event tag has pre-release information: %q", repoName, tagName)
-		return true
-	}
+	// pre-releases are not skipped here, projects opt in to them per dependency
 
 	return false
 }
 
-func processReleaseEvent(ctx context.Context, db data.Client, submitter updater.Submitter, event *github.ReleaseEvent) error {
+// processReleaseEvent submits PRs to the projects depending on the released project. PRs are not
+// submitted when the released version's license is denied, or cannot be detected while licenses
+// are denied. Releases younger than the minimum release age, or every release with a grouping
+// window, are queued instead, to be grouped and submitted by schedulePendingUpdates.
+func processReleaseEvent(ctx context.Context, db data.Client, submitter updater.Submitter, opts releaseOptions, event *github.ReleaseEvent) error {
 	if shouldIgnoreReleaseEvent(event) {
 		return nil
 	}
@@ -245,13 +329,26 @@ func processReleaseEvent(ctx context.Context, db data.Client, submitter updater.
 		return err
 	}
 
+	// licenses are detected, and API compared, once per release for all the projects depending on it
+	var licenses *releaseLicenses
+	if opts.licenses != nil {
+		licenses = newReleaseLicenses(opts.licenses, event)
+	}
+	var api *releaseAPI
+	if opts.api != nil {
+		api = newReleaseAPI(opts.api)
+	}
+	var notes *releaseNotes
+	if opts.notes != nil {
+		notes = newReleaseNotes(opts.notes)
+	}
+
 	for _, k := range keys {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
-			ui(ctx).Info(fmt.Sprintf("submitting PR for %s, bump %s to %s\n", k, repoName, v.String()))
-			project, _, err := db.Project(k)
+			project, deps, err := db.Project(k)
 			if err == data.ErrNotFound {
 				continue
 			}
@@ -259,14 +356,294 @@ func processReleaseEvent(ctx context.Context, db data.Client, submitter updater.
 				return err
 			}
 
-			err = submitter.SubmitPR(ctx, project, depName, v.String())
+			accepted := []updater.Update{}
+			for _, u := range releaseUpdates(deps, depName, v, event.Release.GetPrerelease()) {
+				if d, ok := manifestDependency(deps, u); ok {
+					u = updater.MarkSecurity(opts.advisories, opts.ancestry, d, u)
+				}
+				if !updater.AllowedByPolicy(project.Config, opts.policy, u) {
+					ui(ctx).Info(fmt.Sprintf("skipping %s (%s), bump %s to %s is not allowed by project config", k, u.Manifest, repoName, u.To))
+					continue
+				}
+
+				if licenses != nil && detectsLicenses(opts.detectLicenses, project.Config) {
+					u.FromLicense, u.ToLicense, err = licenses.detect(ctx, u)
+					if err != nil && deniesLicenses(opts, project) {
+						// an undetected license could be a denied one
+						ui(ctx).Warn(fmt.Sprintf("skipping %s (%s), unable to detect licenses of %s: %s", k, u.Manifest, depName, err))
+						continue
+					}
+					if err != nil {
+						// an undetected license cannot be denied, the PR is still submitted
+						ui(ctx).Warn(fmt.Sprintf("unable to detect licenses of %s: %s", depName, err))
+					}
+				}
+				if license.Denied(opts.denyLicenses, u.ToLicense) || project.Config.DeniesLicense(u.ToLicense) {
+					ui(ctx).Info(fmt.Sprintf("skipping %s (%s), %s %s is licensed under denied license %s", k, u.Manifest, repoName, u.To, u.ToLicense))
+					continue
+				}
+
+				if api != nil {
+					gitURL, branch := releaseSource(event)
+					u.APIChanges, err = updater.ReferencedAPIChanges(ctx, api, gitURL, branch, depName, deps, u, tagName)
+					if err != nil {
+						// the PR is still submitted, without its breaking changes
+						ui(ctx).Warn(fmt.Sprintf("unable to compare API of %s: %s", depName, err))
+					}
+				}
+
+				u.ReleaseNotes = event.Release.GetBody()
+				if notes != nil {
+					cl, err := notes.collect(ctx, u)
+					if err != nil {
+						// the PR is still useful without release notes
+						ui(ctx).Warn(fmt.Sprintf("unable to collect release notes of %s: %s", depName, err))
+					} else {
+						u.ReleaseNotes = cl.Markdown()
+					}
+				}
+
+				if (opts.minReleaseAge > 0 || opts.groupWindow > 0) && !u.Security {
+					// queued even when already old enough, so it replaces older queued versions
+					due := releaseDue(event, opts.minReleaseAge)
+					p, err := pendingUpdate(k, u, due)
+					if err != nil {
+						return err
+					}
+					queued, err := db.QueueUpdate(p)
+					if err != nil {
+						return err
+					}
+					if queued {
+						ui(ctx).Info(fmt.Sprintf("holding %s (%s), bump %s to %s until %v", k, u.Manifest, repoName, u.To, due))
+					}
+					continue
+				}
+
+				if u.Security {
+					ui(ctx).Info(fmt.Sprintf("submitting security PR for %s (%s), bump %s to %s fixing %s\n", k, u.Manifest, repoName, u.To, strings.Join(u.Advisories, ", ")))
+				} else if u.Prerelease {
+					ui(ctx).Info(fmt.Sprintf("submitting prerelease PR for %s (%s), bump %s to %s\n", k, u.Manifest, repoName, u.To))
+				} else {
+					ui(ctx).Info(fmt.Sprintf("submitting PR for %s (%s), bump %s to %s\n", k, u.Manifest, repoName, u.To))
+				}
+
+				accepted = append(accepted, u)
+			}
+
+			batches, err := updater.Group(project.Config, accepted)
 			if err != nil {
-				return err
+				// an invalid grouping rule should not hold back the updates
+				ui(ctx).Warn(fmt.Sprintf("unable to group updates for %s: %s", k, err))
+				batches, _ = updater.Group(nil, accepted)
 			}
+			for _, batch := range batches {
+				err = submitter.SubmitBatch(ctx, project, batch)
+				if err != nil {
+					return err
+				}
 
-			ui(ctx).Info("PR submitted")
+				ui(ctx).Info("PR submitted")
+			}
 		}
 	}
 
 	return nil
 }
+
+// releaseDue returns when an update to the release may be submitted.
+func releaseDue(event *github.ReleaseEvent, minAge time.Duration) time.Time {
+	published := event.Release.GetPublishedAt().Time
+	if published.IsZero() {
+		published = time.Now()
+	}
+	return published.Add(minAge)
+}
+
+// deniesLicenses reports if any license is denied to the project.
+func deniesLicenses(opts releaseOptions, project depmap.Project) bool {
+	return len(opts.denyLicenses) > 0 || project.Config != nil && len(project.Config.DenyLicenses) > 0
+}
+
+// releaseLicenses detects the licenses of a released repository, remembering the license, or
+// the detection error, of each revision so projects on the same revision do not detect it again.
+type releaseLicenses struct {
+	detector updater.LicenseDetector
+	event    *github.ReleaseEvent
+	licenses map[string]string
+	errs     map[string]error
+}
+
+func newReleaseLicenses(detector updater.LicenseDetector, event *github.ReleaseEvent) *releaseLicenses {
+	return &releaseLicenses{
+		detector: detector,
+		event:    event,
+		licenses: map[string]string{},
+		errs:     map[string]error{},
+	}
+}
+
+// detect returns the licenses of the released repository at the update's current revision and
+// at the release. The current license is empty when the revision is unknown.
+func (r *releaseLicenses) detect(ctx context.Context, u updater.Update) (string, string, error) {
+	tag := r.event.Release.GetTagName()
+	revisions := []string{tag}
+	if u.FromRevision != "" {
+		revisions = append(revisions, u.FromRevision)
+	}
+
+	missing := []string{}
+	for _, revision := range revisions {
+		if err := r.errs[revision]; err != nil {
+			return "", "", err
+		}
+		if _, ok := r.licenses[revision]; !ok {
+			missing = append(missing, revision)
+		}
+	}
+	if len(missing) > 0 {
+		gitURL, branch := releaseSource(r.event)
+		licenses, err := r.detector.Licenses(ctx, gitURL, branch, missing...)
+		if err != nil {
+			for _, revision := range missing {
+				r.errs[revision] = err
+			}
+			return "", "", err
+		}
+		for i, revision := range missing {
+			r.licenses[revision] = licenses[i]
+		}
+	}
+
+	return r.licenses[u.FromRevision], r.licenses[tag], nil
+}
+
+// releaseNotes collects the release notes of updates, remembering the notes, or the error, of
+// each dependency and version range so projects updating from the same version share them.
+type releaseNotes struct {
+	collector *changelog.Collector
+	notes     map[releaseNotesKey]*changelog.Changelog
+	errs      map[releaseNotesKey]error
+}
+
+type releaseNotesKey struct {
+	dependency, from, to string
+}
+
+func newReleaseNotes(collector *changelog.Collector) *releaseNotes {
+	return &releaseNotes{
+		collector: collector,
+		notes:     map[releaseNotesKey]*changelog.Changelog{},
+		errs:      map[releaseNotesKey]error{},
+	}
+}
+
+func (r *releaseNotes) collect(ctx context.Context, u updater.Update) (*changelog.Changelog, error) {
+	key := releaseNotesKey{u.Name, u.From, u.To}
+	if err := r.errs[key]; err != nil {
+		return nil, err
+	}
+	if cl, ok := r.notes[key]; ok {
+		return cl, nil
+	}
+
+	cl, err := r.collector.Collect(ctx, u)
+	if err != nil {
+		r.errs[key] = err
+		return nil, err
+	}
+	r.notes[key] = cl
+	return cl, nil
+}
+
+// releaseSource returns the git url and default branch of the released repository.
+func releaseSource(event *github.ReleaseEvent) (string, string) {
+	gitURL := event.Repo.GetCloneURL()
+	if gitURL == "" {
+		gitURL = fmt.Sprintf("https://github.com/%s.git", event.Repo.GetName())
+	}
+	branch := event.Repo.GetDefaultBranch()
+	if branch == "" {
+		branch = "master"
+	}
+	return gitURL, branch
+}
+
+// manifestDependency returns the dependency the update applies to, the first package of the
+// updated dependency in the update's manifest.
+func manifestDependency(deps []depmap.Dependency, u updater.Update) (depmap.Dependency, bool) {
+	key := strings.ToLower(u.Name)
+	for _, d := range deps {
+		name := strings.ToLower(d.Name)
+		if name != key && !strings.HasPrefix(name, key+"/") {
+			continue
+		}
+		manifest := d.Manifest
+		if manifest == "" {
+			manifest = "."
+		}
+		if manifest == u.Manifest {
+			return d, true
+		}
+	}
+	return depmap.Dependency{}, false
+}
+
+// releaseUpdates returns an update to the released version for each manifest that declares
+// packages of the dependency, unless the manifest is already on the version or a newer one,
+// as with backport releases. prerelease marks releases flagged as pre-release on GitHub.
+func releaseUpdates(deps []depmap.Dependency, depName string, to semver.Version, prerelease bool) []updater.Update {
+	key := strings.ToLower(depName)
+
+	seen := map[string]bool{}
+	updates := []updater.Update{}
+	for _, d := range deps {
+		name := strings.ToLower(d.Name)
+		if name != key && !strings.HasPrefix(name, key+"/") {
+			continue
+		}
+
+		manifest := d.Manifest
+		if manifest == "" {
+			// registered before manifest discovery, always the root
+			manifest = "."
+		}
+		if seen[manifest] {
+			continue
+		}
+
+		u := updater.Update{
+			Name:       depName,
+			Manifest:   manifest,
+			Manager:    d.Manager,
+			To:         to.String(),
+			Prerelease: prerelease || to.Prerelease() != "",
+
+			FromRevision: d.Revision,
+		}
+		from, err := semver.NewVersion(d.Version)
+		if err == nil {
+			u.From = from.String()
+		}
+		if d.Module {
+			// modules are updated by their module path
+			u.Name = d.Name
+		}
+		u, ok := updater.ModuleUpdate(d, u)
+		if !ok {
+			// released for another major version of the module
+			continue
+		}
+		if u.Migration && updater.RequiresNewerMajor(deps, d) {
+			// the manifest already requires the newer major version, updated in its own path
+			continue
+		}
+		seen[manifest] = true
+		if err == nil && !u.Migration && !to.GreaterThan(from) {
+			// already on the release or a newer one
+			continue
+		}
+		updates = append(updates, u)
+	}
+	return updates
+}
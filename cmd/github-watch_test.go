@@ -1,11 +1,26 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/Masterminds/semver"
+	"github.com/boltdb/bolt"
 	"github.com/google/go-github/github"
+	"github.com/mitchellh/cli"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fresh/go-fresh/changelog"
+	"github.com/go-fresh/go-fresh/data"
+	"github.com/go-fresh/go-fresh/depmap"
+	"github.com/go-fresh/go-fresh/updater"
+	"github.com/go-fresh/go-fresh/vuln"
 )
 
 func TestShouldIgnoreReleaseEvent(t *testing.T) {
@@ -49,19 +64,19 @@ func TestShouldIgnoreReleaseEvent(t *testing.T) {
 			Repo:    &github.Repository{Name: ptrString("foo/bar")},
 			Release: &github.RepositoryRelease{TagName: ptrString("1.2.3.4")},
 		}},
-		{true, &github.ReleaseEvent{
+		{false, &github.ReleaseEvent{
 			Repo:    &github.Repository{Name: ptrString("foo/bar")},
 			Release: &github.RepositoryRelease{TagName: ptrString("v1.2.3-pre")},
 		}},
-		{true, &github.ReleaseEvent{
+		{false, &github.ReleaseEvent{
 			Repo:    &github.Repository{Name: ptrString("foo/bar")},
 			Release: &github.RepositoryRelease{TagName: ptrString("1.2.3-pre")},
 		}},
-		{true, &github.ReleaseEvent{
+		{false, &github.ReleaseEvent{
 			Repo:    &github.Repository{Name: ptrString("foo/bar")},
 			Release: &github.RepositoryRelease{TagName: ptrString("v1.2.3"), Prerelease: ptrBool(true)},
 		}},
-		{true, &github.ReleaseEvent{
+		{false, &github.ReleaseEvent{
 			Repo:    &github.Repository{Name: ptrString("foo/bar")},
 			Release: &github.RepositoryRelease{TagName: ptrString("1.2.3"), Prerelease: ptrBool(true)},
 		}},
@@ -81,3 +96,284 @@ func TestShouldIgnoreReleaseEvent(t *testing.T) {
 		})
 	}
 }
+
+func TestReleaseUpdates(t *testing.T) {
+	deps := []depmap.Dependency{
+		{Name: "github.com/foo/bar", Manifest: ".", Version: "v1.2.0"},
+		{Name: "github.com/foo/bar/baz", Manifest: "services/api"},
+		{Name: "github.com/Foo/Bar/qux", Manifest: "services/api"},
+		{Name: "github.com/foo/barbaz", Manifest: "tools"},
+		{Name: "github.com/foo/bar"},
+	}
+	to, err := semver.NewVersion("v1.3.0")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []updater.Update{
+		{Name: "github.com/foo/bar", Manifest: ".", From: "1.2.0", To: "1.3.0"},
+		{Name: "github.com/foo/bar", Manifest: "services/api", To: "1.3.0"},
+	}, releaseUpdates(deps, "github.com/foo/bar", to, false))
+	assert.Equal(t, []updater.Update{
+		{Name: "github.com/foo/barbaz", Manifest: "tools", To: "1.3.0"},
+	}, releaseUpdates(deps, "github.com/foo/barbaz", to, false))
+	assert.Equal(t, []updater.Update{}, releaseUpdates(deps, "github.com/foo/other", to, false))
+
+	// backports and the current version are not updates
+	for _, version := range []string{"v1.1.9", "v1.2.0"} {
+		backport, err := semver.NewVersion(version)
+		assert.NoError(t, err)
+		assert.Equal(t, []updater.Update{
+			{Name: "github.com/foo/bar", Manifest: "services/api", To: backport.String()},
+		}, releaseUpdates(deps, "github.com/foo/bar", backport, false))
+	}
+	assert.Equal(t, []updater.Update{
+		{Name: "github.com/foo/barbaz", Manifest: "tools", To: "1.3.0", Prerelease: true},
+	}, releaseUpdates(deps, "github.com/foo/barbaz", to, true))
+
+	modules := []depmap.Dependency{
+		{Name: "github.com/foo/bar", Manifest: ".", Version: "v1.2.0", Module: true},
+		{Name: "github.com/foo/bar/v2", Manifest: "services/api", Version: "v2.0.0", Module: true},
+	}
+	v2, err := semver.NewVersion("v2.1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, []updater.Update{
+		{Name: "github.com/foo/bar", Manifest: ".", From: "1.2.0", To: "2.1.0", Bump: updater.UpdateMajor, Migration: true, Path: "github.com/foo/bar/v2"},
+		{Name: "github.com/foo/bar/v2", Manifest: "services/api", From: "2.0.0", To: "2.1.0"},
+	}, releaseUpdates(modules, "github.com/foo/bar", v2, false))
+
+	// a backport to the first major version of a module
+	both := []depmap.Dependency{
+		{Name: "github.com/foo/bar/v2", Manifest: ".", Version: "v2.0.0", Module: true},
+		{Name: "github.com/foo/bar", Manifest: ".", Version: "v1.2.0", Module: true},
+	}
+	assert.Equal(t, []updater.Update{
+		{Name: "github.com/foo/bar", Manifest: ".", From: "1.2.0", To: "1.3.0"},
+	}, releaseUpdates(both, "github.com/foo/bar", to, false))
+	assert.Equal(t, []updater.Update{
+		{Name: "github.com/foo/bar/v2", Manifest: ".", From: "2.0.0", To: "2.1.0"},
+	}, releaseUpdates(both, "github.com/foo/bar", v2, false))
+	assert.Equal(t, []updater.Update{
+		{Name: "github.com/foo/bar", Manifest: ".", From: "1.2.0", To: "1.3.0"},
+	}, releaseUpdates(modules, "github.com/foo/bar", to, false))
+
+	// go.mod lists the first major version before the second
+	sorted := []depmap.Dependency{
+		{Name: "github.com/foo/bar", Manifest: ".", Version: "v1.2.0", Module: true},
+		{Name: "github.com/foo/bar/v2", Manifest: ".", Version: "v2.0.0", Module: true},
+	}
+	assert.Equal(t, []updater.Update{
+		{Name: "github.com/foo/bar/v2", Manifest: ".", From: "2.0.0", To: "2.1.0"},
+	}, releaseUpdates(sorted, "github.com/foo/bar", v2, false))
+	assert.Equal(t, []updater.Update{
+		{Name: "github.com/foo/bar", Manifest: ".", From: "1.2.0", To: "1.3.0"},
+	}, releaseUpdates(sorted, "github.com/foo/bar", to, false))
+
+	rc, err := semver.NewVersion("v1.4.0-rc.1")
+	assert.NoError(t, err)
+	assert.Equal(t, []updater.Update{
+		{Name: "github.com/foo/barbaz", Manifest: "tools", To: "1.4.0-rc.1", Prerelease: true},
+	}, releaseUpdates(deps, "github.com/foo/barbaz", rc, false))
+}
+
+type failingLicenseDetector struct {
+	calls int
+}
+
+func (d *failingLicenseDetector) Licenses(ctx context.Context, gitURL, branch string, revisions ...string) ([]string, error) {
+	d.calls++
+	return nil, errors.New("unable to clone")
+}
+
+func TestProcessReleaseEvent_Licenses(t *testing.T) {
+	assert := require.New(t)
+
+	tmp, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+	bdb, err := bolt.Open(filepath.Join(tmp, "bolt.db"), 0644, nil)
+	assert.NoError(err)
+	defer bdb.Close()
+	db := data.NewBoltClient(bdb)
+
+	deps := []depmap.Dependency{{Name: "github.com/foo/bar", Manifest: ".", Version: "v1.0.0", Revision: "v1.0.0"}}
+	for _, p := range []depmap.Project{
+		{Name: "example.com/foo/ignoring", Config: &depmap.Config{Ignore: []string{"github.com/foo/bar"}}},
+		{Name: "example.com/foo/first"},
+		{Name: "example.com/foo/second"},
+	} {
+		assert.NoError(db.RegisterProject(p, deps))
+	}
+
+	name, tag := "foo/bar", "v1.1.0"
+	event := &github.ReleaseEvent{
+		Repo:    &github.Repository{Name: &name},
+		Release: &github.RepositoryRelease{TagName: &tag},
+	}
+	ctx := context.WithValue(context.Background(), contextKeyUI, cli.Ui(&cli.MockUi{}))
+
+	// without denied licenses an undetected license does not hold back the PRs
+	detector := &failingLicenseDetector{}
+	submitter := &recordingSubmitter{}
+	assert.NoError(processReleaseEvent(ctx, db, submitter, releaseOptions{licenses: detector, detectLicenses: true}, event))
+	assert.Len(submitter.batches, 2)
+	assert.Equal(1, detector.calls)
+
+	// an undetected license could be denied
+	detector = &failingLicenseDetector{}
+	submitter = &recordingSubmitter{}
+	assert.NoError(processReleaseEvent(ctx, db, submitter, releaseOptions{licenses: detector, detectLicenses: true, denyLicenses: []string{"copyleft"}}, event))
+	assert.Empty(submitter.batches)
+	assert.Equal(1, detector.calls)
+}
+
+func TestProcessReleaseEvent_ProjectLicenses(t *testing.T) {
+	assert := require.New(t)
+
+	tmp, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+	bdb, err := bolt.Open(filepath.Join(tmp, "bolt.db"), 0644, nil)
+	assert.NoError(err)
+	defer bdb.Close()
+	db := data.NewBoltClient(bdb)
+
+	deps := []depmap.Dependency{{Name: "github.com/foo/bar", Manifest: ".", Version: "v1.0.0", Revision: "v1.0.0"}}
+	assert.NoError(db.RegisterProject(depmap.Project{Name: "example.com/foo/any"}, deps))
+	assert.NoError(db.RegisterProject(depmap.Project{Name: "example.com/foo/denying", Config: &depmap.Config{DenyLicenses: []string{"copyleft"}}}, deps))
+
+	name, tag := "foo/bar", "v1.1.0"
+	event := &github.ReleaseEvent{
+		Repo:    &github.Repository{Name: &name},
+		Release: &github.RepositoryRelease{TagName: &tag},
+	}
+	ctx := context.WithValue(context.Background(), contextKeyUI, cli.Ui(&cli.MockUi{}))
+
+	// without the license flags, licenses are still detected for the project denying some
+	detector := &failingLicenseDetector{}
+	submitter := &recordingSubmitter{}
+	assert.NoError(processReleaseEvent(ctx, db, submitter, releaseOptions{licenses: detector}, event))
+	assert.Equal(1, detector.calls)
+	assert.Len(submitter.batches, 1)
+}
+
+func TestProcessReleaseEvent_Security(t *testing.T) {
+	assert := require.New(t)
+
+	tmp, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+	bdb, err := bolt.Open(filepath.Join(tmp, "bolt.db"), 0644, nil)
+	assert.NoError(err)
+	defer bdb.Close()
+	db := data.NewBoltClient(bdb)
+
+	project := depmap.Project{Name: "example.com/foo/project", Config: &depmap.Config{Policy: string(updater.PolicyPatch)}}
+	assert.NoError(db.RegisterProject(project, []depmap.Dependency{{Name: "github.com/foo/bar", Manifest: ".", Version: "v1.0.0"}}))
+
+	advisories := &vuln.Database{Advisories: []*vuln.Advisory{{
+		ID: "GO-2018-0001",
+		Affected: []vuln.Affected{{
+			Package: vuln.Package{Ecosystem: "Go", Name: "github.com/foo/bar"},
+			Ranges:  []vuln.Range{{Type: vuln.RangeSemver, Events: []vuln.Event{{Introduced: "0"}, {Fixed: "1.1.0"}}}},
+		}},
+	}}}
+
+	name := "foo/bar"
+	ctx := context.WithValue(context.Background(), contextKeyUI, cli.Ui(&cli.MockUi{}))
+	opts := releaseOptions{advisories: advisories, minReleaseAge: 24 * time.Hour}
+	for _, c := range []struct {
+		tag      string
+		expected int
+	}{
+		// not a fix, held until it is old enough
+		{"v1.0.1", 0},
+		// a minor update fixing the advisory, neither held back by the policy nor the minimum release age
+		{"v1.1.0", 1},
+	} {
+		tag := c.tag
+		event := &github.ReleaseEvent{
+			Repo:    &github.Repository{Name: &name},
+			Release: &github.RepositoryRelease{TagName: &tag},
+		}
+		submitter := &recordingSubmitter{}
+		assert.NoError(processReleaseEvent(ctx, db, submitter, opts, event))
+		assert.Len(submitter.batches, c.expected, tag)
+	}
+}
+
+// countingNotesSource hosts every dependency, with a single release.
+type countingNotesSource struct {
+	calls int
+}
+
+func (s *countingNotesSource) Repository(pkg string) (string, string, bool) {
+	return "foo", "bar", true
+}
+
+func (s *countingNotesSource) Releases(ctx context.Context, owner, repo string) ([]changelog.Release, error) {
+	s.calls++
+	return []changelog.Release{{Tag: "v1.1.0", Body: "Faster"}}, nil
+}
+
+func (s *countingNotesSource) File(ctx context.Context, owner, repo, path, ref string) ([]byte, error) {
+	return nil, changelog.ErrNotFound
+}
+
+func TestProcessReleaseEvent_Notes(t *testing.T) {
+	assert := require.New(t)
+
+	tmp, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+	bdb, err := bolt.Open(filepath.Join(tmp, "bolt.db"), 0644, nil)
+	assert.NoError(err)
+	defer bdb.Close()
+	db := data.NewBoltClient(bdb)
+
+	deps := []depmap.Dependency{{Name: "github.com/foo/bar", Manifest: ".", Version: "v1.0.0"}}
+	for _, p := range []depmap.Project{{Name: "example.com/foo/first"}, {Name: "example.com/foo/second"}} {
+		assert.NoError(db.RegisterProject(p, deps))
+	}
+
+	name, tag := "foo/bar", "v1.1.0"
+	event := &github.ReleaseEvent{
+		Repo:    &github.Repository{Name: &name},
+		Release: &github.RepositoryRelease{TagName: &tag},
+	}
+	ctx := context.WithValue(context.Background(), contextKeyUI, cli.Ui(&cli.MockUi{}))
+
+	// projects updating from the same version share the notes
+	source := &countingNotesSource{}
+	submitter := &recordingSubmitter{}
+	assert.NoError(processReleaseEvent(ctx, db, submitter, releaseOptions{notes: changelog.NewCollector(source)}, event))
+	assert.Len(submitter.batches, 2)
+	assert.Equal(1, source.calls)
+	for _, batch := range submitter.batches {
+		assert.Contains(batch.Updates[0].ReleaseNotes, "Faster")
+	}
+}
+
+func TestProcessReleaseEvent_Policy(t *testing.T) {
+	assert := require.New(t)
+
+	tmp, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+	bdb, err := bolt.Open(filepath.Join(tmp, "bolt.db"), 0644, nil)
+	assert.NoError(err)
+	defer bdb.Close()
+	db := data.NewBoltClient(bdb)
+
+	deps := []depmap.Dependency{{Name: "github.com/foo/bar", Manifest: ".", Version: "v1.0.0"}}
+	assert.NoError(db.RegisterProject(depmap.Project{Name: "example.com/foo/default"}, deps))
+	assert.NoError(db.RegisterProject(depmap.Project{
+		Name:   "example.com/foo/configured",
+		Config: &depmap.Config{Policies: map[string]string{"github.com/foo/bar": "minor"}},
+	}, deps))
+
+	name, tag := "foo/bar", "v1.1.0"
+	event := &github.ReleaseEvent{
+		Repo:    &github.Repository{Name: &name},
+		Release: &github.RepositoryRelease{TagName: &tag},
+	}
+	ctx := context.WithValue(context.Background(), contextKeyUI, cli.Ui(&cli.MockUi{}))
+
+	// the default policy holds back the minor update, unless the project's config allows it
+	submitter := &recordingSubmitter{}
+	assert.NoError(processReleaseEvent(ctx, db, submitter, releaseOptions{policy: updater.PolicyPatch}, event))
+	assert.Len(submitter.batches, 1)
+}
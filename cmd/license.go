@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-fresh/go-fresh/depmap"
+	"github.com/go-fresh/go-fresh/updater"
+)
+
+type licenseCommand struct {
+}
+
+func (c licenseCommand) Flags(m *meta) error {
+	m.Flags.Bool("detect-licenses", false, "detect license changes of released dependencies")
+	m.Flags.StringSlice("deny-licenses", nil, "licenses, SPDX identifiers or kinds such as copyleft, to never update dependencies to")
+
+	return nil
+}
+
+// LicenseDetector returns the license detector, reading licenses from history, whether the
+// licenses of every project's updates are detected, and the denied licenses. Otherwise licenses
+// are only detected for the projects whose configuration denies some.
+func (c licenseCommand) LicenseDetector(ctx context.Context, history *updater.GitHistory) (updater.LicenseDetector, bool, []string, error) {
+	detect, err := flags(ctx).GetBool("detect-licenses")
+	if err != nil {
+		return nil, false, nil, err
+	}
+	deny, err := flags(ctx).GetStringSlice("deny-licenses")
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	if len(deny) > 0 {
+		ui(ctx).Info(fmt.Sprintf("denying licenses %s", strings.Join(deny, ", ")))
+	}
+	return history, detect || len(deny) > 0, deny, nil
+}
+
+// detectsLicenses reports if the licenses of a project's updates are detected, either for all
+// projects or because its configuration denies some.
+func detectsLicenses(detectAll bool, conf *depmap.Config) bool {
+	return detectAll || conf != nil && len(conf.DenyLicenses) > 0
+}
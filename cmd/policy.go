@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/go-fresh/go-fresh/updater"
+)
+
+type policyCommand struct {
+}
+
+func (c policyCommand) Flags(m *meta) error {
+	m.Flags.String("policy", string(updater.PolicyLatest), "update policy of dependencies without one in their project config, patch, minor, latest or latest-in-major")
+
+	return nil
+}
+
+// Policy returns the default update policy, projects' configurations take precedence.
+func (c policyCommand) Policy(ctx context.Context) (updater.Policy, error) {
+	name, err := flags(ctx).GetString("policy")
+	if err != nil {
+		return "", err
+	}
+	return updater.ParsePolicy(name)
+}
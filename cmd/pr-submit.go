@@ -7,6 +7,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/go-fresh/go-fresh/data"
+	"github.com/go-fresh/go-fresh/updater"
 )
 
 type prSubmitCommand struct {
@@ -23,6 +24,7 @@ func PRSubmitCommandFactory(ui cli.Ui) cli.CommandFactory {
 		m.Flags.StringP("project", "p", "", "project for which to submit PR")
 		m.Flags.StringP("dependency", "d", "", "dependency to update")
 		m.Flags.StringP("to-version", "t", "", "uptdate to version")
+		m.Flags.StringP("manifest", "m", ".", "directory of the manifest to update, relative to the project root")
 
 		return m.Register(
 			cmd.boltCommand,
@@ -54,6 +56,10 @@ func (c *prSubmitCommand) Run(ctx context.Context) error {
 		return errors.Errorf("to-version is required")
 	}
 	// TODO: parse toversion to check valid semver?
+	manifest, err := flags(ctx).GetString("manifest")
+	if err != nil {
+		return err
+	}
 
 	bdb, err := c.DB(ctx)
 	if err != nil {
@@ -72,5 +78,9 @@ func (c *prSubmitCommand) Run(ctx context.Context) error {
 		return err
 	}
 
-	return submitter.SubmitPR(ctx, project, dependency, toversion)
+	return submitter.SubmitPR(ctx, project, updater.Update{
+		Name:     dependency,
+		Manifest: manifest,
+		To:       toversion,
+	})
 }
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
 	"os"
 
@@ -13,8 +14,13 @@ import (
 
 type projectRegisterCommand struct {
 	boltCommand
+	cacheCommand
+	githubCommand
+	credentialsCommand
 
-	db data.Client
+	db    data.Client
+	cache *depmap.Cache
+	creds *depmap.Credentials
 }
 
 // ProjectRegisterCommandFactory creates the "project register" command
@@ -28,6 +34,9 @@ func ProjectRegisterCommandFactory(ui cli.Ui) cli.CommandFactory {
 
 		return m.Register(
 			cmd.boltCommand,
+			cmd.cacheCommand,
+			cmd.githubCommand,
+			cmd.credentialsCommand,
 		)
 	})
 }
@@ -59,14 +68,39 @@ func (c *projectRegisterCommand) Run(ctx context.Context) error {
 	defer bdb.Close()
 	c.db = data.NewBoltClient(bdb)
 
+	c.cache, err = c.Cache(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.creds, err = c.Credentials(ctx)
+	if err != nil {
+		return err
+	}
+
 	return c.registerProject(ctx, tmp, p)
 }
 
 func (c *projectRegisterCommand) registerProject(ctx context.Context, tmpDir string, project depmap.Project) error {
-	deps, _, err := project.Dependencies(ctx)
+	manifests, err := project.Dependencies(ctx, &depmap.LoadOptions{
+		Cache:       c.cache,
+		Credentials: c.creds,
+	})
 	if err != nil {
 		return err
 	}
 
+	deps := []depmap.Dependency{}
+	for _, m := range manifests {
+		unused := 0
+		for _, d := range m.Dependencies {
+			if d.Usage == depmap.UsageUnused {
+				unused++
+			}
+		}
+		ui(ctx).Info(fmt.Sprintf("found %s manifest in %q with %d dependencies, %d unused", m.Type, m.Path, len(m.Dependencies), unused))
+		deps = append(deps, m.Dependencies...)
+	}
+
 	return c.db.RegisterProject(project, deps)
 }
@@ -0,0 +1,386 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mitchellh/cli"
+	"github.com/pkg/errors"
+
+	"github.com/go-fresh/go-fresh/data"
+	"github.com/go-fresh/go-fresh/depmap"
+	"github.com/go-fresh/go-fresh/license"
+	"github.com/go-fresh/go-fresh/updater"
+)
+
+type updatesListCommand struct {
+	boltCommand
+	cacheCommand
+	credentialsCommand
+	advisoriesCommand
+	policyCommand
+	licenseCommand
+	apiCommand
+}
+
+// UpdatesListCommandFactory creates the "updates list" command
+func UpdatesListCommandFactory(ui cli.Ui) cli.CommandFactory {
+	cmd := &updatesListCommand{}
+	return newCommandFactory(ui, "updates list", cmd, func(m *meta) error {
+		m.Synopsis = "lists the dependency updates available to registered projects"
+
+		m.Flags.StringP("project", "p", "", "only list updates of this project")
+		m.Flags.StringP("dependency", "d", "", "only list updates of this dependency and its packages")
+		m.Flags.StringSlice("bump", nil, "only list these update types, patch, minor or major")
+		m.Flags.StringP("format", "o", "table", "output format, table, json or markdown")
+		m.Flags.Bool("history", false, "measure how many commits and how long dependencies are behind")
+		m.Flags.Bool("retractions", false, "read the retractions of Go modules, and propose moving off retracted versions")
+		m.Flags.Bool("compare-upstream", false, "report forks whose upstream project released a newer version")
+		m.Flags.Int("workers", updater.DefaultWorkers, "number of sources to query concurrently")
+		m.Flags.Duration("timeout", 0, "limit for each query of a source, zero waits indefinitely")
+
+		return m.Register(
+			cmd.boltCommand,
+			cmd.cacheCommand,
+			cmd.credentialsCommand,
+			cmd.advisoriesCommand,
+			cmd.policyCommand,
+			cmd.licenseCommand,
+			cmd.apiCommand,
+		)
+	})
+}
+
+// projectUpdate is an update available to a registered project.
+type projectUpdate struct {
+	Project string
+	updater.Update
+}
+
+func (c *updatesListCommand) Run(ctx context.Context) error {
+	projectName, err := flags(ctx).GetString("project")
+	if err != nil {
+		return err
+	}
+	dependency, err := flags(ctx).GetString("dependency")
+	if err != nil {
+		return err
+	}
+	bumps, err := flags(ctx).GetStringSlice("bump")
+	if err != nil {
+		return err
+	}
+	format, err := flags(ctx).GetString("format")
+	if err != nil {
+		return err
+	}
+	if _, err := formatUpdates(format, nil); err != nil {
+		return err
+	}
+	history, err := flags(ctx).GetBool("history")
+	if err != nil {
+		return err
+	}
+	retractions, err := flags(ctx).GetBool("retractions")
+	if err != nil {
+		return err
+	}
+	compareUpstream, err := flags(ctx).GetBool("compare-upstream")
+	if err != nil {
+		return err
+	}
+	workers, err := flags(ctx).GetInt("workers")
+	if err != nil {
+		return err
+	}
+	timeout, err := flags(ctx).GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+
+	advisories, err := c.Advisories(ctx)
+	if err != nil {
+		return err
+	}
+	policy, err := c.Policy(ctx)
+	if err != nil {
+		return err
+	}
+
+	opts := updater.Options{
+		CompareUpstream: compareUpstream,
+		Policy:          policy,
+		Advisories:      advisories,
+		Workers:         workers,
+		Timeout:         timeout,
+	}
+	cache, err := c.Cache(ctx)
+	if err != nil {
+		return err
+	}
+	creds, err := c.Credentials(ctx)
+	if err != nil {
+		return err
+	}
+	h := updater.NewGitHistory(cache, creds)
+	if history {
+		opts.History = h
+	}
+	if retractions {
+		opts.Retractions = h
+	}
+	if advisories != nil {
+		opts.Ancestry = h.Ancestry(ctx, timeout)
+	}
+	detector, detectLicenses, denyLicenses, err := c.LicenseDetector(ctx, h)
+	if err != nil {
+		return err
+	}
+	opts.API, err = c.APIAnalyzer(ctx, h)
+	if err != nil {
+		return err
+	}
+
+	// TODO: flag for tmp dir
+	tmp, err := ioutil.TempDir("", "go-fresh")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	bdb, err := c.DB(ctx)
+	if err != nil {
+		return err
+	}
+	defer bdb.Close()
+	db := data.NewBoltClient(bdb)
+	// tags are compared to those of the previous scan
+	opts.Tags = db
+
+	keys := []string{projectName}
+	if projectName == "" {
+		keys, err = db.Projects()
+		if err != nil {
+			return err
+		}
+	}
+
+	found := []projectUpdate{}
+	for _, k := range keys {
+		project, deps, err := db.Project(k)
+		if err != nil {
+			return errors.Wrapf(err, "unable to load project %s", k)
+		}
+
+		// only the listed dependencies and bumps are worth looking up
+		deps = filterDependencies(deps, dependency)
+		conf, ok := bumpConfig(project.Config, bumps)
+		if len(deps) == 0 || !ok {
+			continue
+		}
+
+		projectOpts := opts
+		projectOpts.Config = conf
+		if detectsLicenses(detectLicenses, project.Config) {
+			projectOpts.Licenses = detector
+		}
+		updates, err := updater.List(ctx, tmp, deps, &projectOpts)
+		if listErr, ok := err.(updater.ListError); ok {
+			// the updates of the other dependencies are still listed
+			for _, depErr := range listErr {
+				if _, ok := depErr.Err.(*updater.TagChange); ok {
+					// the code behind the dependency's version changed
+					ui(ctx).Error(fmt.Sprintf("%s: %s", k, depErr))
+					continue
+				}
+				ui(ctx).Warn(fmt.Sprintf("%s: %s", k, depErr))
+			}
+		} else if err != nil {
+			return errors.Wrapf(err, "unable to list updates of %s", k)
+		}
+
+		for _, rootUpdates := range updates {
+			for _, u := range rootUpdates {
+				if license.Denied(denyLicenses, u.ToLicense) {
+					ui(ctx).Info(fmt.Sprintf("%s: skipping %s %s, licensed under denied license %s", k, u.Name, u.To, u.ToLicense))
+					continue
+				}
+				found = append(found, projectUpdate{Project: project.Name, Update: u})
+			}
+		}
+	}
+
+	found = filterUpdates(found, dependency, bumps)
+	out, err := formatUpdates(format, found)
+	if err != nil {
+		return err
+	}
+	ui(ctx).Output(out)
+	ui(ctx).Info(fmt.Sprintf("%d updates available", len(found)))
+	return nil
+}
+
+// matchesDependency reports if name is the dependency or one of its packages, ignoring case.
+func matchesDependency(name, dependency string) bool {
+	name, dependency = strings.ToLower(name), strings.ToLower(dependency)
+	return name == dependency || strings.HasPrefix(name, dependency+"/")
+}
+
+// filterDependencies keeps the dependency and its packages, an empty dependency keeps them all.
+func filterDependencies(deps []depmap.Dependency, dependency string) []depmap.Dependency {
+	if dependency == "" {
+		return deps
+	}
+	filtered := []depmap.Dependency{}
+	for _, d := range deps {
+		if matchesDependency(d.Name, dependency) {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
+
+// bumpConfig narrows the update types the project's configuration allows to the bumps. It
+// reports false when the project allows none of them.
+func bumpConfig(conf *depmap.Config, bumps []string) (*depmap.Config, bool) {
+	if len(bumps) == 0 {
+		return conf, true
+	}
+
+	narrowed := depmap.Config{}
+	if conf != nil {
+		narrowed = *conf
+	}
+	narrowed.Allow = nil
+	for _, b := range bumps {
+		if conf.Allows(b) {
+			narrowed.Allow = append(narrowed.Allow, b)
+		}
+	}
+	return &narrowed, len(narrowed.Allow) > 0
+}
+
+// filterUpdates keeps the updates of the dependency, or its packages, with one of the bumps and
+// sorts them by project, manifest and dependency. Empty filters keep every update.
+func filterUpdates(updates []projectUpdate, dependency string, bumps []string) []projectUpdate {
+	filtered := []projectUpdate{}
+	for _, u := range updates {
+		if dependency != "" && !matchesDependency(u.Name, dependency) {
+			continue
+		}
+		if len(bumps) > 0 && !containsString(bumps, u.Bump) {
+			continue
+		}
+		filtered = append(filtered, u)
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		a, b := filtered[i], filtered[j]
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		if a.Manifest != b.Manifest {
+			return a.Manifest < b.Manifest
+		}
+		return a.Name < b.Name
+	})
+	return filtered
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+var updateColumns = []string{"PROJECT", "MANIFEST", "DEPENDENCY", "FROM", "TO", "BUMP", "NOTES"}
+
+// formatUpdates renders the updates as a table, JSON or a markdown table.
+func formatUpdates(format string, updates []projectUpdate) (string, error) {
+	switch format {
+	case "table":
+		var buf bytes.Buffer
+		w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(updateColumns, "\t"))
+		for _, u := range updates {
+			fmt.Fprintln(w, strings.Join(updateRow(u), "\t"))
+		}
+		if err := w.Flush(); err != nil {
+			return "", err
+		}
+		return strings.TrimRight(buf.String(), "\n"), nil
+	case "json":
+		raw, err := json.MarshalIndent(updates, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(raw), nil
+	case "markdown":
+		lines := []string{
+			"| " + strings.Join(updateColumns, " | ") + " |",
+			"|" + strings.Repeat(" --- |", len(updateColumns)),
+		}
+		for _, u := range updates {
+			lines = append(lines, "| "+strings.Join(updateRow(u), " | ")+" |")
+		}
+		return strings.Join(lines, "\n"), nil
+	}
+	return "", errors.Errorf("unknown format %q", format)
+}
+
+// updateRow returns the updateColumns of an update.
+func updateRow(u projectUpdate) []string {
+	manifest := u.Manifest
+	if manifest == "" {
+		manifest = "."
+	}
+	from := u.From
+	if from == "" {
+		from = "-"
+	}
+	bump := u.Bump
+	if bump == "" {
+		bump = "-"
+	}
+
+	notes := []string{}
+	if u.Security {
+		notes = append(notes, "security "+strings.Join(u.Advisories, ","))
+	}
+	if u.Retracted {
+		notes = append(notes, "retracted "+u.From)
+	}
+	if u.Prerelease {
+		notes = append(notes, "prerelease")
+	}
+	if u.Blocked {
+		notes = append(notes, "blocked by constraint")
+	}
+	if u.Migration {
+		notes = append(notes, "migrate to "+u.Path)
+	}
+	if u.Branch != "" {
+		notes = append(notes, "branch "+u.Branch)
+	}
+	if u.Upstream {
+		notes = append(notes, "upstream")
+	}
+	if u.CommitsBehind > 0 {
+		notes = append(notes, fmt.Sprintf("%d commits behind", u.CommitsBehind))
+	}
+	if len(notes) == 0 {
+		notes = append(notes, "-")
+	}
+
+	return []string{u.Project, manifest, u.Name, from, u.To, bump, strings.Join(notes, ", ")}
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fresh/go-fresh/depmap"
+	"github.com/go-fresh/go-fresh/updater"
+)
+
+func TestFilterUpdates(t *testing.T) {
+	updates := []projectUpdate{
+		{Project: "b", Update: updater.Update{Name: "github.com/foo/bar", To: "1.1.0", Bump: updater.UpdateMinor}},
+		{Project: "a", Update: updater.Update{Name: "github.com/foo/bar/pkg", Manifest: "tools", To: "2.0.0", Bump: updater.UpdateMajor}},
+		{Project: "a", Update: updater.Update{Name: "github.com/foo/barbaz", To: "1.0.1", Bump: updater.UpdatePatch}},
+		{Project: "a", Update: updater.Update{Name: "github.com/foo/bar", To: "1.0.1", Bump: updater.UpdatePatch}},
+	}
+
+	for i, c := range []struct {
+		expected   []int
+		dependency string
+		bumps      []string
+	}{
+		{[]int{3, 2, 1, 0}, "", nil},
+		{[]int{3, 1, 0}, "github.com/Foo/bar", nil},
+		{[]int{3, 2}, "", []string{updater.UpdatePatch}},
+		{[]int{1, 0}, "github.com/foo/bar", []string{updater.UpdateMinor, updater.UpdateMajor}},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			expected := []projectUpdate{}
+			for _, j := range c.expected {
+				expected = append(expected, updates[j])
+			}
+			assert.Equal(t, expected, filterUpdates(updates, c.dependency, c.bumps))
+		})
+	}
+}
+
+func TestFilterDependencies(t *testing.T) {
+	deps := []depmap.Dependency{
+		{Name: "github.com/foo/bar"},
+		{Name: "github.com/foo/bar/pkg"},
+		{Name: "github.com/foo/barbaz"},
+	}
+
+	assert.Equal(t, deps, filterDependencies(deps, ""))
+	assert.Equal(t, deps[:2], filterDependencies(deps, "github.com/Foo/bar"))
+	assert.Empty(t, filterDependencies(deps, "github.com/foo/qux"))
+}
+
+func TestBumpConfig(t *testing.T) {
+	for i, c := range []struct {
+		expected []string
+		ok       bool
+		conf     *depmap.Config
+		bumps    []string
+	}{
+		{nil, true, nil, nil},
+		{[]string{updater.UpdatePatch}, true, nil, []string{updater.UpdatePatch}},
+		{[]string{updater.UpdateMinor}, true, &depmap.Config{Allow: []string{updater.UpdatePatch, updater.UpdateMinor}}, []string{updater.UpdateMinor, updater.UpdateMajor}},
+		{nil, false, &depmap.Config{Allow: []string{updater.UpdatePatch}}, []string{updater.UpdateMajor}},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			conf, ok := bumpConfig(c.conf, c.bumps)
+			require.Equal(t, c.ok, ok)
+			if conf != nil {
+				require.Equal(t, c.expected, conf.Allow)
+			}
+		})
+	}
+}
+
+func TestFormatUpdates(t *testing.T) {
+	assert := require.New(t)
+
+	updates := []projectUpdate{
+		{Project: "example", Update: updater.Update{Name: "github.com/foo/bar", From: "1.0.0", To: "1.0.1", Bump: updater.UpdatePatch}},
+		{Project: "example", Update: updater.Update{Name: "github.com/foo/baz", Manifest: "tools", To: "2.0.0", Security: true, Advisories: []string{"GO-2018-0001"}}},
+	}
+
+	out, err := formatUpdates("table", updates)
+	assert.NoError(err)
+	assert.Equal(`PROJECT  MANIFEST  DEPENDENCY          FROM   TO     BUMP   NOTES
+example  .         github.com/foo/bar  1.0.0  1.0.1  patch  -
+example  tools     github.com/foo/baz  -      2.0.0  -      security GO-2018-0001`, out)
+
+	out, err = formatUpdates("markdown", updates)
+	assert.NoError(err)
+	assert.Equal(`| PROJECT | MANIFEST | DEPENDENCY | FROM | TO | BUMP | NOTES |
+| --- | --- | --- | --- | --- | --- | --- |
+| example | . | github.com/foo/bar | 1.0.0 | 1.0.1 | patch | - |
+| example | tools | github.com/foo/baz | - | 2.0.0 | - | security GO-2018-0001 |`, out)
+
+	out, err = formatUpdates("json", updates[:1])
+	assert.NoError(err)
+	assert.Contains(out, `"Project": "example"`)
+	assert.Contains(out, `"Name": "github.com/foo/bar"`)
+
+	_, err = formatUpdates("yaml", updates)
+	assert.Error(err)
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mitchellh/cli"
+	"github.com/pkg/errors"
+
+	"github.com/go-fresh/go-fresh/data"
+	"github.com/go-fresh/go-fresh/updater"
+)
+
+type vulnScanCommand struct {
+	boltCommand
+	cacheCommand
+	credentialsCommand
+	advisoriesCommand
+}
+
+// VulnScanCommandFactory creates the "vuln scan" command
+func VulnScanCommandFactory(ui cli.Ui) cli.CommandFactory {
+	cmd := &vulnScanCommand{}
+	return newCommandFactory(ui, "vuln scan", cmd, func(m *meta) error {
+		m.Synopsis = "lists registered projects using vulnerable dependency versions"
+
+		m.Flags.StringP("project", "p", "", "only scan this project")
+		m.Flags.Duration("timeout", 0, "limit for each query of a source, zero waits indefinitely")
+
+		return m.Register(
+			cmd.boltCommand,
+			cmd.cacheCommand,
+			cmd.credentialsCommand,
+			cmd.advisoriesCommand,
+		)
+	})
+}
+
+func (c *vulnScanCommand) Run(ctx context.Context) error {
+	projectName, err := flags(ctx).GetString("project")
+	if err != nil {
+		return err
+	}
+	timeout, err := flags(ctx).GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+
+	advisories, err := c.Advisories(ctx)
+	if err != nil {
+		return err
+	}
+	if advisories == nil {
+		return errors.Errorf("advisories is required")
+	}
+
+	cache, err := c.Cache(ctx)
+	if err != nil {
+		return err
+	}
+	creds, err := c.Credentials(ctx)
+	if err != nil {
+		return err
+	}
+	// commits are matched to the advisories' ranges through the history of their repository
+	ancestry := updater.NewGitHistory(cache, creds).Ancestry(ctx, timeout)
+
+	bdb, err := c.DB(ctx)
+	if err != nil {
+		return err
+	}
+	defer bdb.Close()
+	db := data.NewBoltClient(bdb)
+
+	keys := []string{projectName}
+	if projectName == "" {
+		keys, err = db.Projects()
+		if err != nil {
+			return err
+		}
+	}
+
+	affected := 0
+	for _, k := range keys {
+		_, deps, err := db.Project(k)
+		if err != nil {
+			return errors.Wrapf(err, "unable to load project %s", k)
+		}
+
+		for _, d := range deps {
+			for _, f := range advisories.AffectingWith(d, ancestry) {
+				affected++
+
+				version := d.Version
+				if version == "" {
+					version = d.Revision
+				}
+				fixed := f.Fixed
+				if fixed == "" {
+					fixed = "no fix available"
+				}
+				ui(ctx).Output(fmt.Sprintf("%s (%s): %s %s, %s %s, fixed in %s", k, d.Manifest, d.Name, version, f.Advisory.ID, f.Advisory.Summary, fixed))
+			}
+		}
+	}
+
+	ui(ctx).Info(fmt.Sprintf("%d vulnerable dependencies found", affected))
+	return nil
+}
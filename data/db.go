@@ -23,9 +23,17 @@ var ErrNotFound = errors.New("not found")
 
 // Client represents the common functions for a database client.
 type Client interface {
+	Projects() ([]string, error)
 	ProjectsForDependency(dep string) ([]string, error)
 	Project(name string) (depmap.Project, []depmap.Dependency, error)
 	RegisterProject(p depmap.Project, deps []depmap.Dependency) error
+
+	QueueUpdate(p PendingUpdate) (bool, error)
+	PendingUpdates() ([]PendingUpdate, error)
+	RemovePendingUpdate(p PendingUpdate) error
+
+	Tags(source string) (map[string]string, error)
+	RecordTags(source string, tags map[string]string) error
 }
 
 type boltClient struct {
@@ -72,6 +80,24 @@ func depProjectKeyMatch(depKey []byte, test []byte) bool {
 	return bytes.HasPrefix(test, depKey)
 }
 
+func (c *boltClient) Projects() ([]string, error) {
+	projectKeys := []string{}
+	err := c.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketProjects)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			projectKeys = append(projectKeys, string(k))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return projectKeys, nil
+}
+
 func (c *boltClient) ProjectsForDependency(dep string) ([]string, error) {
 	projectKeys := []string{}
 	err := c.db.View(func(tx *bolt.Tx) error {
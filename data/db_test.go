@@ -32,6 +32,10 @@ func TestRegisterProject_RoundTrip(t *testing.T) {
 		Branch: "branch",
 		Name:   projectName,
 		GitURL: "https://example.com/foo/bar.git",
+		Config: &depmap.Config{
+			Ignore:    []string{"dep2"},
+			Reviewers: []string{"octocat"},
+		},
 	}
 	expectedDeps := []depmap.Dependency{
 		{Name: "dep1", Revision: "abcdef"},
@@ -45,6 +49,10 @@ func TestRegisterProject_RoundTrip(t *testing.T) {
 	assert.Equal(expectedProject, actualProject)
 	assert.Equal(expectedDeps, actualDeps)
 
+	projects, err := client.Projects()
+	assert.NoError(err)
+	assert.Equal([]string{"example.com/foo/bar"}, projects)
+
 }
 
 func TestProjectsForDependency(t *testing.T) {
@@ -0,0 +1,125 @@
+package data
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/boltdb/bolt"
+)
+
+var bucketPendingUpdates = []byte("pendingUpdates")
+
+// PendingUpdate is an update held back until its release is old enough to submit.
+type PendingUpdate struct {
+	// Project is the name of the project to update.
+	Project string
+
+	// Name is the dependency to update, Manifest the directory of the manifest declaring
+	// it and To the version to update it to.
+	Name     string
+	Manifest string
+	To       string
+
+	// Update is the encoded update, kept as is for the caller to decode.
+	Update json.RawMessage
+
+	// Due is when the update may be submitted.
+	Due time.Time
+}
+
+func pendingKey(p PendingUpdate) []byte {
+	manifest := p.Manifest
+	if manifest == "" {
+		manifest = "."
+	}
+	return []byte(strings.Join([]string{strings.ToLower(p.Project), manifest, strings.ToLower(p.Name)}, "\x00"))
+}
+
+// QueueUpdate queues an update of a project's dependency, replacing an update of the same
+// dependency to an older version. It reports false, and leaves the queue as it is, when an
+// update to the same or a newer version is already queued.
+func (c *boltClient) QueueUpdate(p PendingUpdate) (bool, error) {
+	queued := false
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(bucketPendingUpdates)
+		if err != nil {
+			return err
+		}
+
+		key := pendingKey(p)
+		var existing PendingUpdate
+		err = getStruct(bucket, key, &existing)
+		if err == nil && !newer(p.To, existing.To) {
+			return nil
+		}
+		if err != nil && err != ErrNotFound {
+			return err
+		}
+
+		queued = true
+		return putStruct(bucket, key, p)
+	})
+	if err != nil {
+		return false, err
+	}
+	return queued, nil
+}
+
+// newer reports if version a is newer than version b, versions that are not semver are
+// compared as strings.
+func newer(a, b string) bool {
+	va, errA := semver.NewVersion(a)
+	vb, errB := semver.NewVersion(b)
+	if errA != nil || errB != nil {
+		return a != b
+	}
+	return va.GreaterThan(vb)
+}
+
+// PendingUpdates returns the queued updates of all projects.
+func (c *boltClient) PendingUpdates() ([]PendingUpdate, error) {
+	pending := []PendingUpdate{}
+	err := c.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketPendingUpdates)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			var p PendingUpdate
+			err := json.Unmarshal(v, &p)
+			if err != nil {
+				return err
+			}
+			pending = append(pending, p)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+// RemovePendingUpdate removes a queued update, unless it has been replaced by an update to
+// another version.
+func (c *boltClient) RemovePendingUpdate(p PendingUpdate) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketPendingUpdates)
+		if bucket == nil {
+			return nil
+		}
+
+		key := pendingKey(p)
+		var existing PendingUpdate
+		err := getStruct(bucket, key, &existing)
+		if err == ErrNotFound || err == nil && existing.To != p.To {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return bucket.Delete(key)
+	})
+}
@@ -0,0 +1,69 @@
+package data
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingUpdates(t *testing.T) {
+	assert := require.New(t)
+
+	tmp, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+
+	bdb, err := bolt.Open(filepath.Join(tmp, "bolt.db"), 0644, nil)
+	assert.NoError(err)
+	defer bdb.Close()
+
+	client := NewBoltClient(bdb)
+
+	pending, err := client.PendingUpdates()
+	assert.NoError(err)
+	assert.Empty(pending)
+
+	due := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	v110 := PendingUpdate{Project: "example.com/foo/bar", Name: "github.com/foo/dep", Manifest: ".", To: "1.1.0", Update: []byte(`{"To":"1.1.0"}`), Due: due}
+	v111 := PendingUpdate{Project: "example.com/foo/bar", Name: "github.com/foo/dep", Manifest: ".", To: "1.1.1", Update: []byte(`{"To":"1.1.1"}`), Due: due.Add(time.Hour)}
+	tools := PendingUpdate{Project: "example.com/foo/bar", Name: "github.com/foo/dep", Manifest: "tools", To: "1.1.0", Due: due}
+
+	queued, err := client.QueueUpdate(v110)
+	assert.NoError(err)
+	assert.True(queued)
+	queued, err = client.QueueUpdate(tools)
+	assert.NoError(err)
+	assert.True(queued)
+
+	// superseded by a newer version
+	queued, err = client.QueueUpdate(v111)
+	assert.NoError(err)
+	assert.True(queued)
+
+	// older or repeated versions leave the queue as it is
+	queued, err = client.QueueUpdate(v110)
+	assert.NoError(err)
+	assert.False(queued)
+	queued, err = client.QueueUpdate(v111)
+	assert.NoError(err)
+	assert.False(queued)
+
+	pending, err = client.PendingUpdates()
+	assert.NoError(err)
+	assert.Len(pending, 2)
+	assert.Equal("1.1.1", pending[0].To)
+	assert.JSONEq(`{"To":"1.1.1"}`, string(pending[0].Update))
+	assert.Equal(due.Add(time.Hour), pending[0].Due.UTC())
+	assert.Equal("tools", pending[1].Manifest)
+
+	// removing a replaced update keeps its replacement
+	assert.NoError(client.RemovePendingUpdate(v110))
+	assert.NoError(client.RemovePendingUpdate(tools))
+	pending, err = client.PendingUpdates()
+	assert.NoError(err)
+	assert.Len(pending, 1)
+	assert.Equal("1.1.1", pending[0].To)
+}
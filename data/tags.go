@@ -0,0 +1,38 @@
+package data
+
+import (
+	"github.com/boltdb/bolt"
+)
+
+var bucketTags = []byte("tags")
+
+// Tags returns the revision of each tag recorded for a dependency source, or nil if none were.
+func (c *boltClient) Tags(source string) (map[string]string, error) {
+	var tags map[string]string
+	err := c.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketTags)
+		if bucket == nil {
+			return nil
+		}
+		err := getStruct(bucket, []byte(source), &tags)
+		if err == ErrNotFound {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// RecordTags replaces the tags recorded for a dependency source.
+func (c *boltClient) RecordTags(source string, tags map[string]string) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(bucketTags)
+		if err != nil {
+			return err
+		}
+		return putStruct(bucket, []byte(source), tags)
+	})
+}
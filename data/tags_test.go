@@ -0,0 +1,40 @@
+package data
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTags(t *testing.T) {
+	assert := require.New(t)
+
+	tmp, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+
+	bdb, err := bolt.Open(filepath.Join(tmp, "bolt.db"), 0644, nil)
+	assert.NoError(err)
+	defer bdb.Close()
+
+	client := NewBoltClient(bdb)
+
+	tags, err := client.Tags("github.com/foo/bar")
+	assert.NoError(err)
+	assert.Nil(tags)
+
+	assert.NoError(client.RecordTags("github.com/foo/bar", map[string]string{"v1.0.0": "abc", "v1.1.0": "def"}))
+	assert.NoError(client.RecordTags("github.com/foo/baz", map[string]string{"v2.0.0": "123"}))
+
+	tags, err = client.Tags("github.com/foo/bar")
+	assert.NoError(err)
+	assert.Equal(map[string]string{"v1.0.0": "abc", "v1.1.0": "def"}, tags)
+
+	// recording replaces the previous tags
+	assert.NoError(client.RecordTags("github.com/foo/bar", map[string]string{"v1.1.0": "fed"}))
+	tags, err = client.Tags("github.com/foo/bar")
+	assert.NoError(err)
+	assert.Equal(map[string]string{"v1.1.0": "fed"}, tags)
+}
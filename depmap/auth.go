@@ -0,0 +1,142 @@
+package depmap
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+)
+
+// AuthError is returned when a repository can not be retrieved because credentials
+// are missing, invalid or rejected by the Git host.
+type AuthError struct {
+	URL string
+	Err error
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("authentication failed for %s: %s", e.URL, e.Err)
+}
+
+// Unwrap returns the underlying go-git or SSH error.
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// HostCredentials are the credentials used for all repositories on a Git host.
+type HostCredentials struct {
+	// Username and password, or token, for HTTPS URLs.
+	Username string `hcl:"username"`
+	Password string `hcl:"password"`
+	Token    string `hcl:"token"`
+
+	// Private key, such as a deploy key, for SSH URLs.
+	SSHUser          string `hcl:"ssh_user"`
+	SSHKeyFile       string `hcl:"ssh_key_file"`
+	SSHKeyPassphrase string `hcl:"ssh_key_passphrase"`
+}
+
+// Credentials resolves the authentication to use when cloning a repository.
+type Credentials struct {
+	// GithubToken is used for HTTPS URLs on github.com without host credentials.
+	GithubToken string
+
+	// Hosts maps Git host names to their credentials.
+	Hosts map[string]HostCredentials `hcl:"host"`
+}
+
+// LoadCredentials reads per host credentials from an HCL file:
+//
+//	host "github.com" {
+//	  token = "..."
+//	}
+//
+//	host "git.example.com" {
+//	  ssh_key_file = "/etc/go-fresh/deploy_key"
+//	}
+func LoadCredentials(path string) (*Credentials, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read credentials file %s", path)
+	}
+
+	creds := &Credentials{}
+	err = hcl.Decode(creds, string(raw))
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse credentials file %s", path)
+	}
+
+	return creds, nil
+}
+
+// AuthMethod returns the go-git authentication for the URL, or nil if none is configured.
+func (c *Credentials) AuthMethod(gitURL string) (transport.AuthMethod, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	ep, err := transport.NewEndpoint(gitURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse Git URL %s", gitURL)
+	}
+
+	host, ok := c.Hosts[ep.Host]
+
+	switch ep.Protocol {
+	case "http", "https":
+		switch {
+		case ok && host.Token != "":
+			return tokenAuth(host.Token), nil
+		case ok && host.Username != "":
+			return &http.BasicAuth{Username: host.Username, Password: host.Password}, nil
+		case ep.Host == "github.com" && c.GithubToken != "":
+			return tokenAuth(c.GithubToken), nil
+		}
+	case "ssh":
+		if !ok || host.SSHKeyFile == "" {
+			// fall back to the SSH agent
+			return nil, nil
+		}
+
+		user := host.SSHUser
+		if user == "" {
+			user = ep.User
+		}
+		if user == "" {
+			user = "git"
+		}
+
+		auth, err := ssh.NewPublicKeysFromFile(user, host.SSHKeyFile, host.SSHKeyPassphrase)
+		if err != nil {
+			return nil, &AuthError{
+				URL: gitURL,
+				Err: errors.Wrapf(err, "unable to load SSH key %s", host.SSHKeyFile),
+			}
+		}
+		return auth, nil
+	}
+
+	return nil, nil
+}
+
+func tokenAuth(token string) transport.AuthMethod {
+	// the username is ignored by GitHub and most other hosts when using a token, but must not be empty
+	return &http.BasicAuth{Username: "go-fresh", Password: token}
+}
+
+// wrapAuthError converts go-git authentication failures in to an AuthError.
+func wrapAuthError(gitURL string, err error) error {
+	switch {
+	case err == transport.ErrAuthenticationRequired,
+		err == transport.ErrAuthorizationFailed,
+		err == transport.ErrInvalidAuthMethod,
+		err != nil && strings.Contains(err.Error(), "unable to authenticate"):
+		return &AuthError{URL: gitURL, Err: err}
+	}
+	return err
+}
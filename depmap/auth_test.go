@@ -0,0 +1,83 @@
+package depmap
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+)
+
+func TestLoadCredentials(t *testing.T) {
+	assert := require.New(t)
+
+	creds, err := LoadCredentials(filepath.Join("testdata", "credentials.hcl"))
+	assert.NoError(err)
+	assert.Equal(map[string]HostCredentials{
+		"git.example.com": {Username: "bot", Password: "secret"},
+		"gitlab.com":      {Token: "abc123"},
+		"ssh.example.com": {SSHKeyFile: "testdata/missing_key"},
+	}, creds.Hosts)
+}
+
+func TestCredentialsAuthMethod(t *testing.T) {
+	creds := &Credentials{
+		GithubToken: "ghtoken",
+		Hosts: map[string]HostCredentials{
+			"git.example.com": {Username: "bot", Password: "secret"},
+			"gitlab.com":      {Token: "abc123"},
+		},
+	}
+
+	for i, c := range []struct {
+		expected transport.AuthMethod
+		url      string
+	}{
+		{&http.BasicAuth{Username: "go-fresh", Password: "ghtoken"}, "https://github.com/foo/bar.git"},
+		{&http.BasicAuth{Username: "go-fresh", Password: "abc123"}, "https://gitlab.com/foo/bar.git"},
+		{&http.BasicAuth{Username: "bot", Password: "secret"}, "https://git.example.com/foo/bar.git"},
+		{nil, "https://bitbucket.org/foo/bar.git"},
+		{nil, "git@github.com:foo/bar.git"},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert := require.New(t)
+
+			actual, err := creds.AuthMethod(c.url)
+			assert.NoError(err)
+			assert.Equal(c.expected, actual)
+		})
+	}
+}
+
+func TestCredentialsAuthMethod_MissingKey(t *testing.T) {
+	assert := require.New(t)
+
+	tmp, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+	defer os.RemoveAll(tmp)
+
+	creds := &Credentials{
+		Hosts: map[string]HostCredentials{
+			"ssh.example.com": {SSHKeyFile: filepath.Join(tmp, "missing_key")},
+		},
+	}
+
+	_, err = creds.AuthMethod("git@ssh.example.com:foo/bar.git")
+	assert.IsType(&AuthError{}, errors.Cause(err))
+}
+
+func TestWrapAuthError(t *testing.T) {
+	assert := require.New(t)
+
+	err := wrapAuthError("https://github.com/foo/bar.git", transport.ErrAuthenticationRequired)
+	assert.IsType(&AuthError{}, err)
+	assert.Equal(transport.ErrAuthenticationRequired, err.(*AuthError).Unwrap())
+
+	err = wrapAuthError("https://github.com/foo/bar.git", transport.ErrEmptyRemoteRepository)
+	assert.Equal(transport.ErrEmptyRemoteRepository, err)
+}
@@ -0,0 +1,334 @@
+package depmap
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+)
+
+// Cache is an on disk cache of cloned repositories, keyed by Git URL. Repositories
+// already in the cache are fetched incrementally instead of cloned again.
+type Cache struct {
+	// Dir is the directory holding the cached repositories.
+	Dir string
+
+	// MaxSize is the total size in bytes the cache is trimmed to after each use,
+	// removing the least recently used repositories first. Zero disables the cap.
+	MaxSize int64
+
+	// MaxAge removes repositories that have not been used for longer than the duration.
+	// Zero disables expiry.
+	MaxAge time.Duration
+
+	// mu guards inUse and locks only, it is never held during network or disk I/O
+	mu sync.Mutex
+	// inUse counts the callers of Open that have not released each entry yet, cleanup
+	// leaves these entries alone.
+	inUse map[string]int
+	// locks serializes cloning and fetching each entry.
+	locks map[string]chan struct{}
+}
+
+// NewCache creates a repository cache in dir.
+func NewCache(dir string, maxSize int64, maxAge time.Duration) (*Cache, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to create cache directory %s", dir)
+	}
+
+	return &Cache{
+		Dir:     dir,
+		MaxSize: maxSize,
+		MaxAge:  maxAge,
+	}, nil
+}
+
+func cacheKey(gitURL string) string {
+	sum := sha1.Sum([]byte(gitURL))
+	return hex.EncodeToString(sum[:])
+}
+
+// BranchReference returns the reference to clone a branch from, the remote's HEAD, its
+// default branch, when branch is empty.
+func BranchReference(branch string) plumbing.ReferenceName {
+	if branch == "" {
+		return plumbing.HEAD
+	}
+	return plumbing.ReferenceName(fmt.Sprintf("refs/heads/%s", branch))
+}
+
+// Open returns the cached repository for the URL with the branch checked out, the default
+// branch when it is empty, cloning or fetching it as necessary. The repository is kept in
+// the cache until Release is called for the URL.
+func (c *Cache) Open(ctx context.Context, gitURL, branch string, auth transport.AuthMethod) (*git.Repository, error) {
+	return c.open(ctx, gitURL, branch, auth, true)
+}
+
+// Fetch is like Open but leaves the work tree alone and fetches all tags too, for callers
+// only reading history. The branch is available as its remote tracking reference.
+func (c *Cache) Fetch(ctx context.Context, gitURL, branch string, auth transport.AuthMethod) (*git.Repository, error) {
+	return c.open(ctx, gitURL, branch, auth, false)
+}
+
+func (c *Cache) open(ctx context.Context, gitURL, branch string, auth transport.AuthMethod, checkout bool) (*git.Repository, error) {
+	key := cacheKey(gitURL)
+
+	// the entry is marked in use before any I/O so cleanup never removes it underneath,
+	// and only callers of the same URL wait for each other
+	lock := c.acquire(key)
+	select {
+	case lock <- struct{}{}:
+	case <-ctx.Done():
+		c.Release(gitURL)
+		return nil, errors.Wrapf(ctx.Err(), "unable to open cached repository %s", gitURL)
+	}
+
+	repo, err := c.sync(ctx, key, gitURL, branch, auth, checkout)
+	<-lock
+	if err != nil {
+		c.Release(gitURL)
+		return nil, err
+	}
+
+	err = c.cleanup()
+	if err != nil {
+		c.Release(gitURL)
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// acquire marks the entry in use and returns the lock serializing its updates.
+func (c *Cache) acquire(key string) chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.inUse == nil {
+		c.inUse = map[string]int{}
+		c.locks = map[string]chan struct{}{}
+	}
+	c.inUse[key]++
+
+	lock, ok := c.locks[key]
+	if !ok {
+		lock = make(chan struct{}, 1)
+		c.locks[key] = lock
+	}
+	return lock
+}
+
+// sync clones or fetches the entry, the caller holds its lock.
+func (c *Cache) sync(ctx context.Context, key, gitURL, branch string, auth transport.AuthMethod, checkout bool) (*git.Repository, error) {
+	dir := filepath.Join(c.Dir, key)
+	refName := BranchReference(branch)
+	remoteBranch := branch
+	if remoteBranch == "" {
+		remoteBranch = string(plumbing.HEAD)
+	}
+	remoteRefName := plumbing.ReferenceName(fmt.Sprintf("refs/remotes/%s/%s", git.DefaultRemoteName, remoteBranch))
+
+	repo, err := git.PlainOpen(dir)
+	switch {
+	case err == git.ErrRepositoryNotExists:
+		repo, err = git.PlainCloneContext(ctx, dir, false, &git.CloneOptions{
+			URL:           gitURL,
+			Auth:          auth,
+			ReferenceName: refName,
+			// go-git assumes a single branch cloned from HEAD is master
+			SingleBranch: branch != "",
+			Tags:         git.AllTags,
+		})
+		if err != nil {
+			os.RemoveAll(dir)
+			return nil, errors.Wrapf(wrapAuthError(gitURL, err), "unable to clone repository %s", gitURL)
+		}
+	case err != nil:
+		return nil, errors.Wrapf(err, "unable to open cached repository %s", dir)
+	default:
+		refSpecs := []config.RefSpec{
+			config.RefSpec(fmt.Sprintf("+%s:%s", refName, remoteRefName)),
+		}
+		if !checkout {
+			// released versions are not necessarily on the branch
+			refSpecs = append(refSpecs, "+refs/tags/*:refs/tags/*")
+		}
+
+		err = repo.FetchContext(ctx, &git.FetchOptions{
+			RefSpecs: refSpecs,
+			Auth:     auth,
+		})
+		if err != nil && err != git.NoErrAlreadyUpToDate {
+			return nil, errors.Wrapf(wrapAuthError(gitURL, err), "unable to fetch repository %s", gitURL)
+		}
+
+		if !checkout {
+			break
+		}
+
+		ref, err := repo.Reference(remoteRefName, true)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to resolve %s", remoteRefName)
+		}
+
+		tree, err := repo.Worktree()
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to load work tree")
+		}
+
+		err = tree.Checkout(&git.CheckoutOptions{
+			Hash:  ref.Hash(),
+			Force: true,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to check out %s", ref.Hash())
+		}
+	}
+
+	// the modification time of the entry tracks its last use
+	now := time.Now()
+	err = os.Chtimes(dir, now, now)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to touch cache entry %s", dir)
+	}
+
+	return repo, nil
+}
+
+// Release signals the repository of the URL returned by Open is no longer used, so cleanup
+// may remove it.
+func (c *Cache) Release(gitURL string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := cacheKey(gitURL)
+	if c.inUse[key] <= 1 {
+		// nobody holds or waits for the lock once the entry is unused
+		delete(c.inUse, key)
+		delete(c.locks, key)
+		return
+	}
+	c.inUse[key]--
+}
+
+// Cleanup removes expired repositories, then the least recently used ones until the
+// cache fits in MaxSize.
+func (c *Cache) Cleanup() error {
+	return c.cleanup()
+}
+
+type cacheEntry struct {
+	key     string
+	size    int64
+	lastUse time.Time
+}
+
+// cleanup removes expired and least recently used entries, except those in use. The
+// entries are measured without holding the cache lock, so other callers are not blocked
+// by the walk.
+func (c *Cache) cleanup() error {
+	infos, err := ioutil.ReadDir(c.Dir)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read cache directory %s", c.Dir)
+	}
+
+	entries := make([]cacheEntry, 0, len(infos))
+	var total int64
+
+	for _, info := range infos {
+		if !info.IsDir() {
+			continue
+		}
+
+		e := cacheEntry{
+			key:     info.Name(),
+			lastUse: info.ModTime(),
+		}
+
+		if c.MaxAge > 0 && time.Since(e.lastUse) > c.MaxAge {
+			removed, err := c.remove(e.key)
+			if err != nil {
+				return errors.Wrapf(err, "unable to remove expired cache entry %s", e.key)
+			}
+			if removed {
+				continue
+			}
+		}
+
+		e.size, err = dirSize(filepath.Join(c.Dir, e.key))
+		if err != nil {
+			return err
+		}
+		total += e.size
+		entries = append(entries, e)
+	}
+
+	if c.MaxSize <= 0 || total <= c.MaxSize {
+		return nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].lastUse.Before(entries[j].lastUse)
+	})
+
+	for _, e := range entries {
+		if total <= c.MaxSize {
+			break
+		}
+
+		removed, err := c.remove(e.key)
+		if err != nil {
+			return errors.Wrapf(err, "unable to remove cache entry %s", e.key)
+		}
+		if removed {
+			total -= e.size
+		}
+	}
+
+	return nil
+}
+
+// remove deletes the entry unless it is in use, reporting whether it did.
+func (c *Cache) remove(key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.inUse[key] > 0 {
+		return false, nil
+	}
+	return true, os.RemoveAll(filepath.Join(c.Dir, key))
+}
+
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			// a concurrent fetch renamed or removed the file
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to calculate size of %s", dir)
+	}
+	return size, nil
+}
@@ -0,0 +1,218 @@
+package depmap
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func TestCacheCleanup(t *testing.T) {
+	assert := require.New(t)
+
+	tmp, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+	defer os.RemoveAll(tmp)
+
+	cache, err := NewCache(tmp, 250, 24*time.Hour)
+	assert.NoError(err)
+
+	now := time.Now()
+	for name, age := range map[string]time.Duration{
+		"expired": 48 * time.Hour,
+		"old":     3 * time.Hour,
+		"recent":  2 * time.Hour,
+		"newest":  1 * time.Hour,
+	} {
+		dir := filepath.Join(tmp, name)
+		assert.NoError(os.Mkdir(dir, 0755))
+		assert.NoError(ioutil.WriteFile(filepath.Join(dir, "data"), make([]byte, 100), 0644))
+		assert.NoError(os.Chtimes(dir, now.Add(-age), now.Add(-age)))
+	}
+
+	assert.NoError(cache.Cleanup())
+
+	infos, err := ioutil.ReadDir(tmp)
+	assert.NoError(err)
+
+	remaining := []string{}
+	for _, info := range infos {
+		remaining = append(remaining, info.Name())
+	}
+	assert.Equal([]string{"newest", "recent"}, remaining)
+}
+
+func TestCacheCleanup_InUse(t *testing.T) {
+	assert := require.New(t)
+
+	tmp, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+	defer os.RemoveAll(tmp)
+
+	cache, err := NewCache(tmp, 50, time.Hour)
+	assert.NoError(err)
+
+	now := time.Now()
+	for name, age := range map[string]time.Duration{
+		"expired": 2 * time.Hour,
+		"old":     30 * time.Minute,
+		"newest":  10 * time.Minute,
+	} {
+		dir := filepath.Join(tmp, cacheKey(name))
+		assert.NoError(os.Mkdir(dir, 0755))
+		assert.NoError(ioutil.WriteFile(filepath.Join(dir, "data"), make([]byte, 100), 0644))
+		assert.NoError(os.Chtimes(dir, now.Add(-age), now.Add(-age)))
+	}
+
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(tmp, cacheKey(name)))
+		return err == nil
+	}
+
+	// entries opened by other callers are neither expired nor evicted
+	cache.inUse = map[string]int{cacheKey("expired"): 1, cacheKey("old"): 2}
+	assert.NoError(cache.Cleanup())
+	assert.True(exists("expired"))
+	assert.True(exists("old"))
+	assert.False(exists("newest"))
+
+	cache.Release("expired")
+	cache.Release("old")
+	assert.NoError(cache.Cleanup())
+	assert.False(exists("expired"))
+	assert.True(exists("old"))
+
+	cache.Release("old")
+	assert.NoError(cache.Cleanup())
+	assert.False(exists("old"))
+}
+
+func TestCacheOpen_DefaultBranch(t *testing.T) {
+	assert := require.New(t)
+
+	src, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+	defer os.RemoveAll(src)
+
+	// a repository whose default branch is not master
+	repo, err := git.PlainInit(src, false)
+	assert.NoError(err)
+	assert.NoError(repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, "refs/heads/main")))
+	tree, err := repo.Worktree()
+	assert.NoError(err)
+
+	sig := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	commit := func(content string) plumbing.Hash {
+		assert.NoError(ioutil.WriteFile(filepath.Join(src, "README"), []byte(content), 0644))
+		_, err := tree.Add("README")
+		assert.NoError(err)
+		hash, err := tree.Commit(content, &git.CommitOptions{Author: sig, Committer: sig})
+		assert.NoError(err)
+		return hash
+	}
+
+	tmp, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+	defer os.RemoveAll(tmp)
+	cache, err := NewCache(tmp, 0, 0)
+	assert.NoError(err)
+
+	// cloned, and then fetched once cached
+	for _, content := range []string{"first", "second"} {
+		hash := commit(content)
+
+		cached, err := cache.Open(context.Background(), src, "", nil)
+		assert.NoError(err)
+		head, err := cached.Head()
+		assert.NoError(err)
+		assert.Equal(hash, head.Hash())
+		cache.Release(src)
+	}
+}
+
+func TestCacheFetch(t *testing.T) {
+	assert := require.New(t)
+
+	src, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+	defer os.RemoveAll(src)
+
+	repo, err := git.PlainInit(src, false)
+	assert.NoError(err)
+	tree, err := repo.Worktree()
+	assert.NoError(err)
+
+	sig := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	commit := func(content string) plumbing.Hash {
+		assert.NoError(ioutil.WriteFile(filepath.Join(src, "README"), []byte(content), 0644))
+		_, err := tree.Add("README")
+		assert.NoError(err)
+		hash, err := tree.Commit(content, &git.CommitOptions{Author: sig, Committer: sig})
+		assert.NoError(err)
+		return hash
+	}
+
+	tmp, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+	defer os.RemoveAll(tmp)
+	cache, err := NewCache(tmp, 0, 0)
+	assert.NoError(err)
+
+	commit("first")
+	_, err = cache.Open(context.Background(), src, "master", nil)
+	assert.NoError(err)
+	cache.Release(src)
+
+	// a tag off the branch, and a new commit on it
+	hash := commit("second")
+	_, err = repo.CreateTag("v1.0.0", hash, nil)
+	assert.NoError(err)
+	hash = commit("third")
+
+	cached, err := cache.Fetch(context.Background(), src, "master", nil)
+	assert.NoError(err)
+	defer cache.Release(src)
+
+	ref, err := cached.Reference("refs/remotes/origin/master", true)
+	assert.NoError(err)
+	assert.Equal(hash, ref.Hash())
+	_, err = cached.Tag("v1.0.0")
+	assert.NoError(err)
+
+	// the work tree is left alone
+	content, err := ioutil.ReadFile(filepath.Join(tmp, cacheKey(src), "README"))
+	assert.NoError(err)
+	assert.Equal("first", string(content))
+}
+
+func TestCacheOpen_Locked(t *testing.T) {
+	assert := require.New(t)
+
+	tmp, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+	defer os.RemoveAll(tmp)
+	cache, err := NewCache(tmp, 0, 0)
+	assert.NoError(err)
+
+	// another caller is still cloning the URL
+	lock := cache.acquire(cacheKey("busy"))
+	lock <- struct{}{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = cache.Open(ctx, "busy", "", nil)
+	assert.Error(err)
+	assert.Equal(1, cache.inUse[cacheKey("busy")])
+
+	// other URLs are not blocked
+	_, err = cache.Open(context.Background(), filepath.Join(tmp, "missing"), "", nil)
+	assert.Error(err)
+	assert.NotContains(err.Error(), "deadline")
+}
@@ -0,0 +1,217 @@
+package depmap
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/hcl"
+	"github.com/pkg/errors"
+	billy "gopkg.in/src-d/go-billy.v4"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/go-fresh/go-fresh/license"
+)
+
+// Config is a repository's own go-fresh configuration, read from a .gofresh.yml or .gofresh.hcl
+// file in the root of the project.
+type Config struct {
+	// Ignore lists dependencies, including all their packages, that are never updated.
+	Ignore []string `yaml:"ignore" hcl:"ignore" json:",omitempty"`
+
+	// Pin maps dependencies to a version, or semver constraint, updates must satisfy.
+	Pin map[string]string `yaml:"pin" hcl:"pin" json:",omitempty"`
+
+	// Policy is the update policy for all dependencies, "patch", "minor", "latest" or "latest-in-major".
+	Policy string `yaml:"policy" hcl:"policy" json:",omitempty"`
+
+	// Policies maps dependencies to their own update policy.
+	Policies map[string]string `yaml:"policies" hcl:"policies" json:",omitempty"`
+
+	// Prereleases opts dependencies in to prerelease versions. Each maps to the allowed prerelease
+	// identifiers, such as "rc", an empty list allows every prerelease.
+	Prereleases map[string][]string `yaml:"prereleases" hcl:"prereleases" json:",omitempty"`
+
+	// Track maps dependencies to how they are updated, "tags" proposes semver releases and "branch"
+	// the head of the dependency's branch, "branch:<name>" tracks the named branch instead.
+	// Without an entry, dependencies whose project has no semver tags track a branch.
+	Track map[string]string `yaml:"track" hcl:"track" json:",omitempty"`
+
+	// Allow lists the allowed update types, "patch", "minor" or "major". Empty allows all of them.
+	Allow []string `yaml:"allow" hcl:"allow" json:",omitempty"`
+
+	// DenyLicenses lists licenses, as SPDX identifiers or kinds such as "copyleft", dependencies
+	// must not be updated to.
+	DenyLicenses []string `yaml:"deny_licenses" hcl:"deny_licenses" json:",omitempty"`
+
+	// Group is how updates are combined into PRs, "none", "prefix", "bump" or "all".
+	// GroupPrefixes are the dependency prefixes combined by the "prefix" rule.
+	Group         string   `yaml:"group" hcl:"group" json:",omitempty"`
+	GroupPrefixes []string `yaml:"group_prefixes" hcl:"group_prefixes" json:",omitempty"`
+
+	// TargetBranch is the branch to submit PRs to, instead of the registered branch.
+	TargetBranch string `yaml:"target_branch" hcl:"target_branch" json:",omitempty"`
+
+	// Reviewers and Labels are added to submitted PRs.
+	Reviewers []string `yaml:"reviewers" hcl:"reviewers" json:",omitempty"`
+	Labels    []string `yaml:"labels" hcl:"labels" json:",omitempty"`
+}
+
+var configFiles = []string{
+	".gofresh.yml",
+	".gofresh.yaml",
+	".gofresh.hcl",
+}
+
+// LoadConfig reads the go-fresh configuration from the root of the file system. It returns nil if
+// the repository has no configuration.
+func LoadConfig(fs billy.Filesystem) (*Config, error) {
+	for _, name := range configFiles {
+		raw, err := readFile(fs, name)
+		if err == errFileNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read config file %s", name)
+		}
+
+		conf := &Config{}
+		if strings.HasSuffix(name, ".hcl") {
+			err = hcl.Decode(conf, string(raw))
+		} else {
+			err = yaml.Unmarshal(raw, conf)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse config file %s", name)
+		}
+		return conf, nil
+	}
+
+	return nil, nil
+}
+
+// Ignored reports if the package belongs to an ignored dependency.
+func (c *Config) Ignored(pkg string) bool {
+	if c == nil {
+		return false
+	}
+	for _, ignore := range c.Ignore {
+		if matchesDependency(pkg, ignore) {
+			return true
+		}
+	}
+	return false
+}
+
+// Pinned returns the version constraint the package is pinned to, if any.
+func (c *Config) Pinned(pkg string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	if dep, ok := bestMatch(pkg, keys(c.Pin)); ok {
+		return c.Pin[dep], true
+	}
+	return "", false
+}
+
+// bestMatch returns the most specific of the dependencies the package belongs to, so overlapping
+// dependencies match the same way whatever order they are listed in.
+func bestMatch(pkg string, deps []string) (string, bool) {
+	best, found := "", false
+	for _, dep := range deps {
+		if matchesDependency(pkg, dep) && (!found || len(dep) > len(best)) {
+			best, found = dep, true
+		}
+	}
+	return best, found
+}
+
+func keys(m map[string]string) []string {
+	ks := make([]string, 0, len(m))
+	for k := range m {
+		ks = append(ks, k)
+	}
+	return ks
+}
+
+// PolicyFor returns the update policy for the package, or an empty string if none is configured.
+func (c *Config) PolicyFor(pkg string) string {
+	if c == nil {
+		return ""
+	}
+	if dep, ok := bestMatch(pkg, keys(c.Policies)); ok {
+		return c.Policies[dep]
+	}
+	return c.Policy
+}
+
+// TrackingFor returns how the package is tracked, or an empty string if none is configured.
+func (c *Config) TrackingFor(pkg string) string {
+	if c == nil {
+		return ""
+	}
+	if dep, ok := bestMatch(pkg, keys(c.Track)); ok {
+		return c.Track[dep]
+	}
+	return ""
+}
+
+// AllowsPrerelease reports if the package opted in to prerelease versions with the prerelease
+// information, for example "rc.1" or "beta2". Packages that did not opt in never allow prereleases.
+func (c *Config) AllowsPrerelease(pkg, prerelease string) bool {
+	if c == nil {
+		return false
+	}
+	deps := make([]string, 0, len(c.Prereleases))
+	for dep := range c.Prereleases {
+		deps = append(deps, dep)
+	}
+	dep, ok := bestMatch(pkg, deps)
+	if !ok {
+		return false
+	}
+
+	identifiers := c.Prereleases[dep]
+	if len(identifiers) == 0 {
+		return true
+	}
+	id := prereleaseIdentifier(prerelease)
+	for _, allowed := range identifiers {
+		if allowed == id {
+			return true
+		}
+	}
+	return false
+}
+
+// prereleaseIdentifier returns the leading identifier of semver prerelease information,
+// "rc.1", "rc1" and "rc-1" are all "rc".
+func prereleaseIdentifier(prerelease string) string {
+	end := strings.IndexFunc(prerelease, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if end < 0 {
+		return prerelease
+	}
+	return prerelease[:end]
+}
+
+// Allows reports if the update type, "patch", "minor" or "major", is allowed.
+func (c *Config) Allows(updateType string) bool {
+	if c == nil || len(c.Allow) == 0 {
+		return true
+	}
+	for _, allowed := range c.Allow {
+		if allowed == updateType {
+			return true
+		}
+	}
+	return false
+}
+
+// DeniesLicense reports if the license, an SPDX identifier, is on the deny list.
+func (c *Config) DeniesLicense(id string) bool {
+	if c == nil {
+		return false
+	}
+	return license.Denied(c.DenyLicenses, id)
+}
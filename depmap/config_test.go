@@ -0,0 +1,134 @@
+package depmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	expected := &Config{
+		Ignore:        []string{"github.com/aws/aws-sdk-go"},
+		Pin:           map[string]string{"github.com/pkg/errors": "~0.8.0"},
+		Prereleases:   map[string][]string{"github.com/foo/bar": {"rc"}},
+		Track:         map[string]string{"github.com/foo/baz": "branch"},
+		Allow:         []string{"patch", "minor"},
+		DenyLicenses:  []string{"AGPL-3.0", "copyleft"},
+		Group:         "prefix",
+		GroupPrefixes: []string{"github.com/aws/"},
+		TargetBranch:  "develop",
+		Reviewers:     []string{"octocat"},
+		Labels:        []string{"dependencies"},
+	}
+
+	for _, fixture := range []string{"config-yml", "config-hcl"} {
+		t.Run(fixture, func(t *testing.T) {
+			assert := require.New(t)
+
+			conf, err := LoadConfig(fixtureFS(t, fixture))
+			assert.NoError(err)
+			assert.Equal(expected, conf)
+
+			assert.True(conf.Ignored("github.com/aws/aws-sdk-go/service/s3"))
+			assert.False(conf.Ignored("github.com/aws/aws-sdk-go-v2"))
+
+			pin, ok := conf.Pinned("github.com/pkg/errors")
+			assert.True(ok)
+			assert.Equal("~0.8.0", pin)
+
+			assert.True(conf.Allows("minor"))
+			assert.False(conf.Allows("major"))
+
+			assert.True(conf.AllowsPrerelease("github.com/foo/bar", "rc.1"))
+			assert.False(conf.AllowsPrerelease("github.com/foo/bar", "alpha"))
+			assert.False(conf.AllowsPrerelease("github.com/pkg/errors", "rc.1"))
+
+			assert.Equal("branch", conf.TrackingFor("github.com/foo/baz/qux"))
+			assert.Equal("", conf.TrackingFor("github.com/foo/bar"))
+
+			assert.True(conf.DeniesLicense("AGPL-3.0"))
+			assert.True(conf.DeniesLicense("GPL-2.0"))
+			assert.False(conf.DeniesLicense("MIT"))
+		})
+	}
+}
+
+func TestLoadConfig_None(t *testing.T) {
+	assert := require.New(t)
+
+	conf, err := LoadConfig(fixtureFS(t, "empty"))
+	assert.NoError(err)
+	assert.Nil(conf)
+
+	assert.False(conf.Ignored("github.com/pkg/errors"))
+	assert.True(conf.Allows("major"))
+	assert.False(conf.AllowsPrerelease("github.com/pkg/errors", "rc.1"))
+	assert.False(conf.DeniesLicense("AGPL-3.0"))
+}
+
+func TestConfig_Overlapping(t *testing.T) {
+	assert := require.New(t)
+
+	conf := &Config{
+		Pin: map[string]string{
+			"github.com/aws":             "^1.0.0",
+			"github.com/aws/aws-sdk-go":  "~1.12.0",
+			"github.com/aws/aws-sdk-go2": "^2.0.0",
+		},
+		Policy: "latest",
+		Policies: map[string]string{
+			"github.com/aws":            "minor",
+			"github.com/aws/aws-sdk-go": "patch",
+		},
+		Prereleases: map[string][]string{
+			"github.com/aws":            {},
+			"github.com/aws/aws-sdk-go": {"rc"},
+		},
+		Track: map[string]string{
+			"github.com/aws":            "tags",
+			"github.com/aws/aws-sdk-go": "branch:develop",
+		},
+	}
+
+	// repeated, as maps are iterated in random order
+	for i := 0; i < 20; i++ {
+		pin, ok := conf.Pinned("github.com/aws/aws-sdk-go/service/s3")
+		assert.True(ok)
+		assert.Equal("~1.12.0", pin)
+
+		pin, ok = conf.Pinned("github.com/aws/aws-lambda-go")
+		assert.True(ok)
+		assert.Equal("^1.0.0", pin)
+
+		_, ok = conf.Pinned("github.com/awslabs/goformation")
+		assert.False(ok)
+
+		assert.Equal("patch", conf.PolicyFor("github.com/aws/aws-sdk-go/service/s3"))
+		assert.Equal("minor", conf.PolicyFor("github.com/aws/aws-lambda-go"))
+		assert.Equal("latest", conf.PolicyFor("github.com/awslabs/goformation"))
+
+		assert.True(conf.AllowsPrerelease("github.com/aws/aws-sdk-go/service/s3", "rc.1"))
+		assert.False(conf.AllowsPrerelease("github.com/aws/aws-sdk-go/service/s3", "beta.1"))
+		assert.True(conf.AllowsPrerelease("github.com/aws/aws-lambda-go", "beta.1"))
+		assert.False(conf.AllowsPrerelease("github.com/awslabs/goformation", "rc.1"))
+
+		assert.Equal("branch:develop", conf.TrackingFor("github.com/aws/aws-sdk-go/service/s3"))
+		assert.Equal("tags", conf.TrackingFor("github.com/aws/aws-lambda-go"))
+		assert.Equal("", conf.TrackingFor("github.com/awslabs/goformation"))
+	}
+}
+
+func TestPrereleaseIdentifier(t *testing.T) {
+	for prerelease, expected := range map[string]string{
+		"rc":      "rc",
+		"rc1":     "rc",
+		"rc.1":    "rc",
+		"beta-2":  "beta",
+		"alpha.0": "alpha",
+		"1":       "",
+	} {
+		t.Run(prerelease, func(t *testing.T) {
+			require.Equal(t, expected, prereleaseIdentifier(prerelease))
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package depmap
+
+import (
+	"path"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"github.com/pkg/errors"
+	billy "gopkg.in/src-d/go-billy.v4"
+)
+
+type depLock struct {
+	Projects []depLockedProject `toml:"projects"`
+}
+
+type depLockedProject struct {
+	Name     string   `toml:"name"`
+	Branch   string   `toml:"branch"`
+	Revision string   `toml:"revision"`
+	Version  string   `toml:"version"`
+	Source   string   `toml:"source"`
+	Packages []string `toml:"packages"`
+}
+
+type depManifest struct {
+	Constraints []depProjectProperties `toml:"constraint"`
+	Overrides   []depProjectProperties `toml:"override"`
+}
+
+type depProjectProperties struct {
+	Name     string `toml:"name"`
+	Branch   string `toml:"branch"`
+	Revision string `toml:"revision"`
+	Version  string `toml:"version"`
+	Source   string `toml:"source"`
+}
+
+const (
+	gopkgLock = "Gopkg.lock"
+	gopkgToml = "Gopkg.toml"
+)
+
+type depManager struct{}
+
+func (depManager) Name() string {
+	return "dep"
+}
+
+func (depManager) Detect(fs billy.Filesystem) (bool, error) {
+	return fileExists(fs, gopkgLock)
+}
+
+func (depManager) Parse(fs billy.Filesystem) ([]Dependency, error) {
+	raw, err := readFile(fs, gopkgLock)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read dep lock file %s", gopkgLock)
+	}
+
+	lock := depLock{}
+	err = toml.Unmarshal(raw, &lock)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to unmarshal dep lock file %s", gopkgLock)
+	}
+
+	manifest := depManifest{}
+	raw, err = readFile(fs, gopkgToml)
+	switch {
+	case err == errFileNotFound:
+		// a lock without a manifest has no constraints, but is still usable
+	case err != nil:
+		return nil, errors.Wrapf(err, "unable to read dep manifest file %s", gopkgToml)
+	default:
+		err = toml.Unmarshal(raw, &manifest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to unmarshal dep manifest file %s", gopkgToml)
+		}
+	}
+
+	constraints := map[string]depProjectProperties{}
+	for _, c := range manifest.Constraints {
+		constraints[c.Name] = c
+	}
+	// overrides supersede any constraint, including those of transitive deps
+	for _, o := range manifest.Overrides {
+		constraints[o.Name] = o
+	}
+
+	deps := []Dependency{}
+
+	for _, p := range lock.Projects {
+		c := constraints[p.Name]
+
+		packages := p.Packages
+		if len(packages) == 0 {
+			packages = []string{"."}
+		}
+
+		branch := p.Branch
+		if branch == "" {
+			branch = c.Branch
+		}
+		constraint := ""
+		if c.Revision == "" {
+			// a revision pins the project, there is no version range to update within
+			constraint = depConstraint(c.Version)
+		}
+
+		for _, pkg := range packages {
+			deps = append(deps, Dependency{
+				Name:       path.Join(p.Name, pkg),
+				Revision:   p.Revision,
+				Source:     p.Source,
+				Version:    p.Version,
+				Branch:     branch,
+				Constraint: constraint,
+			})
+		}
+	}
+
+	return deps, nil
+}
+
+// depConstraint converts a dep version constraint to a semver range. dep treats a bare version
+// as a caret range, "0.8.0" allows "^0.8.0", where semver ranges match it exactly.
+func depConstraint(version string) string {
+	version = strings.TrimSpace(version)
+	if version == "" || strings.ContainsAny(version, " ,|") {
+		return version
+	}
+
+	bare := strings.TrimPrefix(version, "v")
+	if bare != "" && bare[0] >= '0' && bare[0] <= '9' {
+		return "^" + version
+	}
+	return version
+}
@@ -0,0 +1,54 @@
+package depmap
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepManager(t *testing.T) {
+	assert := require.New(t)
+
+	fs := fixtureFS(t, "dep")
+	used, err := depManager{}.Detect(fs)
+	assert.NoError(err)
+	assert.True(used)
+
+	deps, err := depManager{}.Parse(fs)
+	assert.NoError(err)
+	assert.Equal([]Dependency{
+		{Name: "github.com/Masterminds/semver", Revision: "3c92f33da7a84de8314f3ff82e5f919b89fd1492", Branch: "2.x"},
+		{Name: "github.com/pkg/errors", Revision: "645ef00459ed84a119197bfb8d8205042c6df63d", Version: "v0.8.0", Constraint: "^0.8.0"},
+		{Name: "github.com/sirupsen/logrus", Revision: "d682213848ed68c0a260ca37d6dd5ace8423f5ba", Branch: "develop"},
+		{Name: "github.com/ugorji/go/codec", Revision: "8c0409fcbb70099c748d71f714529204975f6c3f", Source: "github.com/example/ugorji-go"},
+		{Name: "gopkg.in/src-d/go-billy.v4", Revision: "83cf655d40b15b427014d7875d10850f96edba14", Version: "v4.2.0", Constraint: "~4.2.0"},
+		{Name: "gopkg.in/src-d/go-billy.v4/memfs", Revision: "83cf655d40b15b427014d7875d10850f96edba14", Version: "v4.2.0", Constraint: "~4.2.0"},
+	}, deps)
+}
+
+func TestDepManager_NotUsed(t *testing.T) {
+	assert := require.New(t)
+
+	used, err := depManager{}.Detect(fixtureFS(t, "empty"))
+	assert.NoError(err)
+	assert.False(used)
+}
+
+func TestDepConstraint(t *testing.T) {
+	for i, c := range []struct {
+		version  string
+		expected string
+	}{
+		{"0.8.0", "^0.8.0"},
+		{"v1.2", "^v1.2"},
+		{"~4.2.0", "~4.2.0"},
+		{"=1.0.0", "=1.0.0"},
+		{">= 1.0.0, < 2.0.0", ">= 1.0.0, < 2.0.0"},
+		{"", ""},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			require.Equal(t, c.expected, depConstraint(c.version))
+		})
+	}
+}
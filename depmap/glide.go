@@ -0,0 +1,103 @@
+package depmap
+
+import (
+	"path"
+
+	"github.com/pkg/errors"
+	billy "gopkg.in/src-d/go-billy.v4"
+	yaml "gopkg.in/yaml.v2"
+)
+
+type glideLock struct {
+	Imports     []glideLockedImport `yaml:"imports"`
+	TestImports []glideLockedImport `yaml:"testImports"`
+}
+
+type glideLockedImport struct {
+	Name        string   `yaml:"name"`
+	Version     string   `yaml:"version"`
+	Repo        string   `yaml:"repo"`
+	Subpackages []string `yaml:"subpackages"`
+}
+
+type glideConfig struct {
+	Imports     []glideDependency `yaml:"import"`
+	TestImports []glideDependency `yaml:"testImport"`
+}
+
+type glideDependency struct {
+	Package string `yaml:"package"`
+	Version string `yaml:"version"`
+	Repo    string `yaml:"repo"`
+}
+
+const (
+	glideLockFile = "glide.lock"
+	glideYAML     = "glide.yaml"
+)
+
+type glideManager struct{}
+
+func (glideManager) Name() string {
+	return "glide"
+}
+
+func (glideManager) Detect(fs billy.Filesystem) (bool, error) {
+	return fileExists(fs, glideLockFile)
+}
+
+func (glideManager) Parse(fs billy.Filesystem) ([]Dependency, error) {
+	raw, err := readFile(fs, glideLockFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read glide lock file %s", glideLockFile)
+	}
+
+	lock := glideLock{}
+	err = yaml.Unmarshal(raw, &lock)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to unmarshal glide lock file %s", glideLockFile)
+	}
+
+	conf := glideConfig{}
+	raw, err = readFile(fs, glideYAML)
+	switch {
+	case err == errFileNotFound:
+		// lock alone is enough to know what is vendored
+	case err != nil:
+		return nil, errors.Wrapf(err, "unable to read glide config file %s", glideYAML)
+	default:
+		err = yaml.Unmarshal(raw, &conf)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to unmarshal glide config file %s", glideYAML)
+		}
+	}
+
+	constraints := map[string]string{}
+	for _, d := range append(conf.Imports, conf.TestImports...) {
+		constraints[d.Package] = d.Version
+	}
+
+	deps := []Dependency{}
+
+	for _, imp := range append(lock.Imports, lock.TestImports...) {
+		dep := Dependency{
+			Name:       imp.Name,
+			Revision:   imp.Version,
+			Source:     imp.Repo,
+			Constraint: constraints[imp.Name],
+		}
+
+		if len(imp.Subpackages) == 0 {
+			deps = append(deps, dep)
+			continue
+		}
+
+		for _, sub := range imp.Subpackages {
+			subdep := dep
+			subdep.Name = path.Join(imp.Name, sub)
+			deps = append(deps, subdep)
+		}
+	}
+
+	return deps, nil
+}
@@ -0,0 +1,33 @@
+package depmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlideManager(t *testing.T) {
+	assert := require.New(t)
+
+	fs := fixtureFS(t, "glide")
+	used, err := glideManager{}.Detect(fs)
+	assert.NoError(err)
+	assert.True(used)
+
+	deps, err := glideManager{}.Parse(fs)
+	assert.NoError(err)
+	assert.Equal([]Dependency{
+		{Name: "github.com/pkg/errors", Revision: "645ef00459ed84a119197bfb8d8205042c6df63d", Constraint: "^0.8.0"},
+		{Name: "gopkg.in/src-d/go-billy.v4/memfs", Revision: "83cf655d40b15b427014d7875d10850f96edba14", Source: "https://github.com/example/go-billy", Constraint: "~4.2.0"},
+		{Name: "gopkg.in/src-d/go-billy.v4/osfs", Revision: "83cf655d40b15b427014d7875d10850f96edba14", Source: "https://github.com/example/go-billy", Constraint: "~4.2.0"},
+		{Name: "github.com/stretchr/testify/require", Revision: "f35b8ab0b5a2cef36673838d662e249dd9c94686", Constraint: "1.2.2"},
+	}, deps)
+}
+
+func TestGlideManager_NotUsed(t *testing.T) {
+	assert := require.New(t)
+
+	used, err := glideManager{}.Detect(fixtureFS(t, "empty"))
+	assert.NoError(err)
+	assert.False(used)
+}
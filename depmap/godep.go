@@ -0,0 +1,91 @@
+package depmap
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	billy "gopkg.in/src-d/go-billy.v4"
+)
+
+const godepsJSON = "Godeps/Godeps.json"
+
+type godepsFile struct {
+	ImportPath   string
+	GoVersion    string
+	GodepVersion string
+	Packages     []string `json:",omitempty"`
+	Deps         []godepsDependency
+}
+
+type godepsDependency struct {
+	ImportPath string
+	Comment    string `json:",omitempty"`
+	Rev        string
+}
+
+type godepManager struct{}
+
+func (godepManager) Name() string {
+	return "godep"
+}
+
+func (godepManager) Detect(fs billy.Filesystem) (bool, error) {
+	return fileExists(fs, godepsJSON)
+}
+
+func (godepManager) Parse(fs billy.Filesystem) ([]Dependency, error) {
+	gf, err := readGodepsFile(fs)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := make([]Dependency, 0, len(gf.Deps))
+
+	for _, d := range gf.Deps {
+		deps = append(deps, Dependency{
+			Name:     d.ImportPath,
+			Revision: d.Rev,
+			// godep records the output of `git describe --tags` as the comment
+			Version: d.Comment,
+		})
+	}
+
+	return deps, nil
+}
+
+func (godepManager) ApplyUpdate(fs billy.Filesystem, dependency, revision, version string) error {
+	gf, err := readGodepsFile(fs)
+	if err != nil {
+		return err
+	}
+
+	for i, d := range gf.Deps {
+		if !matchesDependency(d.ImportPath, dependency) {
+			continue
+		}
+		gf.Deps[i].Rev = revision
+		gf.Deps[i].Comment = version
+	}
+
+	raw, err := json.MarshalIndent(gf, "", "\t")
+	if err != nil {
+		return errors.Wrapf(err, "unable to marshal godep file %s", godepsJSON)
+	}
+
+	return writeFile(fs, godepsJSON, append(raw, '\n'))
+}
+
+func readGodepsFile(fs billy.Filesystem) (*godepsFile, error) {
+	raw, err := readFile(fs, godepsJSON)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read godep file %s", godepsJSON)
+	}
+
+	gf := &godepsFile{}
+	err = json.Unmarshal(raw, gf)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to unmarshal godep file %s", godepsJSON)
+	}
+
+	return gf, nil
+}
@@ -0,0 +1,32 @@
+package depmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGodepManager(t *testing.T) {
+	assert := require.New(t)
+
+	fs := fixtureFS(t, "godep")
+	used, err := godepManager{}.Detect(fs)
+	assert.NoError(err)
+	assert.True(used)
+
+	deps, err := godepManager{}.Parse(fs)
+	assert.NoError(err)
+	assert.Equal([]Dependency{
+		{Name: "github.com/pkg/errors", Revision: "645ef00459ed84a119197bfb8d8205042c6df63d", Version: "v0.8.0"},
+		{Name: "golang.org/x/oauth2", Revision: "ef147856a6ddbb60760db74283d2424e98c87bff"},
+		{Name: "golang.org/x/oauth2/internal", Revision: "ef147856a6ddbb60760db74283d2424e98c87bff"},
+	}, deps)
+}
+
+func TestGodepManager_NotUsed(t *testing.T) {
+	assert := require.New(t)
+
+	used, err := godepManager{}.Detect(fixtureFS(t, "empty"))
+	assert.NoError(err)
+	assert.False(used)
+}
@@ -0,0 +1,154 @@
+package depmap
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	billy "gopkg.in/src-d/go-billy.v4"
+)
+
+const goMod = "go.mod"
+
+type gomodManager struct{}
+
+func (gomodManager) Name() string {
+	return "gomod"
+}
+
+func (gomodManager) Detect(fs billy.Filesystem) (bool, error) {
+	return fileExists(fs, goMod)
+}
+
+func (gomodManager) Parse(fs billy.Filesystem) ([]Dependency, error) {
+	mf, err := readModFile(fs)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := make([]Dependency, 0, len(mf.Require))
+
+	for _, req := range mf.Require {
+		if req == nil {
+			continue
+		}
+
+		dep := Dependency{
+			Name:     req.Mod.Path,
+			Revision: moduleRevision(req.Mod.Version),
+			Version:  req.Mod.Version,
+			Indirect: req.Indirect,
+			Module:   true,
+		}
+
+		for _, rep := range mf.Replace {
+			if rep.Old.Path != req.Mod.Path {
+				continue
+			}
+			if rep.Old.Version != "" && rep.Old.Version != req.Mod.Version {
+				continue
+			}
+
+			dep.Source = rep.New.Path
+			if rep.New.Version != "" {
+				// a versioned replacement is what actually gets built
+				dep.Revision = moduleRevision(rep.New.Version)
+				dep.Version = rep.New.Version
+			}
+		}
+
+		for _, ex := range mf.Exclude {
+			if ex.Mod.Path != req.Mod.Path {
+				continue
+			}
+			dep.Excluded = append(dep.Excluded, ex.Mod.Version)
+		}
+
+		deps = append(deps, dep)
+	}
+
+	return deps, nil
+}
+
+func (gomodManager) ApplyUpdate(fs billy.Filesystem, dependency, revision, version string) error {
+	mf, err := readModFile(fs)
+	if err != nil {
+		return err
+	}
+
+	err = mf.AddRequire(dependency, version)
+	if err != nil {
+		return errors.Wrapf(err, "unable to require %s %s", dependency, version)
+	}
+	mf.Cleanup()
+
+	raw, err := mf.Format()
+	if err != nil {
+		return errors.Wrapf(err, "unable to format go modules file %s", goMod)
+	}
+
+	return writeFile(fs, goMod, raw)
+}
+
+func readModFile(fs billy.Filesystem) (*modfile.File, error) {
+	raw, err := readFile(fs, goMod)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read go modules file %s", goMod)
+	}
+
+	mf, err := modfile.Parse(goMod, raw, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse go modules file %s", goMod)
+	}
+
+	return mf, nil
+}
+
+// Retraction is a range of versions the authors of a module retracted, Low and High included.
+type Retraction struct {
+	Low, High string
+	Rationale string
+}
+
+// Retracts reports if the module version is in the retracted range.
+func (r Retraction) Retracts(version string) bool {
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	if !semver.IsValid(version) {
+		return false
+	}
+	return semver.Compare(version, r.Low) >= 0 && semver.Compare(version, r.High) <= 0
+}
+
+// ParseRetractions returns the module path and retractions of a go.mod file, as the go command
+// reads them from the go.mod of a dependency's latest version.
+func ParseRetractions(filename string, raw []byte) (string, []Retraction, error) {
+	mf, err := modfile.ParseLax(filename, raw, nil)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "unable to parse go modules file %s", filename)
+	}
+	if mf.Module == nil {
+		return "", nil, errors.Errorf("go modules file %s declares no module", filename)
+	}
+
+	retractions := make([]Retraction, 0, len(mf.Retract))
+	for _, r := range mf.Retract {
+		retractions = append(retractions, Retraction{Low: r.Low, High: r.High, Rationale: r.Rationale})
+	}
+	return mf.Module.Mod.Path, retractions, nil
+}
+
+// moduleRevision converts a module version to the revision or tag it points to.
+// Pseudo-versions resolve to their commit hash, tagged versions to the tag.
+func moduleRevision(version string) string {
+	if module.IsPseudoVersion(version) {
+		rev, err := module.PseudoVersionRev(version)
+		if err == nil {
+			return rev
+		}
+	}
+	return strings.TrimSuffix(version, "+incompatible")
+}
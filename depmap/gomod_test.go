@@ -0,0 +1,64 @@
+package depmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGomodManager(t *testing.T) {
+	assert := require.New(t)
+
+	fs := fixtureFS(t, "gomod")
+	used, err := gomodManager{}.Detect(fs)
+	assert.NoError(err)
+	assert.True(used)
+
+	deps, err := gomodManager{}.Parse(fs)
+	assert.NoError(err)
+	assert.Equal([]Dependency{
+		{Name: "github.com/pkg/errors", Revision: "v0.8.0", Version: "v0.8.0", Excluded: []string{"v0.8.1"}, Module: true},
+		{Name: "github.com/stretchr/testify", Revision: "v1.2.2", Version: "v1.2.2", Indirect: true, Module: true},
+		{Name: "golang.org/x/oauth2", Revision: "ef147856a6dd", Version: "v0.0.0-20180620175406-ef147856a6dd", Module: true},
+		{Name: "gopkg.in/src-d/go-git.v4", Revision: "v4.4.2-fork", Version: "v4.4.2-fork", Source: "github.com/example/go-git", Module: true},
+		{Name: "github.com/google/go-github", Revision: "v15.0.0", Version: "v15.0.0+incompatible", Module: true},
+	}, deps)
+}
+
+func TestGomodManager_NotUsed(t *testing.T) {
+	assert := require.New(t)
+
+	used, err := gomodManager{}.Detect(fixtureFS(t, "empty"))
+	assert.NoError(err)
+	assert.False(used)
+}
+
+func TestParseRetractions(t *testing.T) {
+	assert := require.New(t)
+
+	path, retractions, err := ParseRetractions("go.mod", []byte(`module github.com/foo/bar/v2
+
+go 1.16
+
+retract (
+	// tag was moved
+	v2.1.0
+	[v2.2.0, v2.2.3] // broken build
+)
+`))
+	assert.NoError(err)
+	assert.Equal("github.com/foo/bar/v2", path)
+	assert.Equal([]Retraction{
+		{Low: "v2.1.0", High: "v2.1.0", Rationale: "tag was moved"},
+		{Low: "v2.2.0", High: "v2.2.3", Rationale: "broken build"},
+	}, retractions)
+
+	assert.True(retractions[0].Retracts("v2.1.0"))
+	assert.True(retractions[1].Retracts("2.2.1"))
+	assert.True(retractions[1].Retracts("v2.2.3+incompatible"))
+	assert.False(retractions[1].Retracts("v2.2.4"))
+	assert.False(retractions[1].Retracts("master"))
+
+	_, _, err = ParseRetractions("go.mod", []byte("go 1.16\n"))
+	assert.Error(err)
+}
@@ -1,29 +1,32 @@
 package depmap
 
 import (
-	"os"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/kardianos/govendor/vendorfile"
 	"github.com/pkg/errors"
-	git "gopkg.in/src-d/go-git.v4"
+	billy "gopkg.in/src-d/go-billy.v4"
 )
 
-func tryGovendor(tree *git.Worktree) ([]Dependency, string, error) {
-	const vendorJSON = "vendor/vendor.json"
+const vendorJSON = "vendor/vendor.json"
 
-	f, err := tree.Filesystem.Open(vendorJSON)
-	if err != nil {
-		if err == os.ErrNotExist {
-			return nil, "", errDepSystemNotUsed
-		}
-		return nil, "", errors.Wrapf(err, "unable to open file govendor vendor file %s", vendorJSON)
-	}
-	defer f.Close()
+type govendorManager struct{}
 
-	vf := &vendorfile.File{}
-	err = vf.Unmarshal(f)
+func (govendorManager) Name() string {
+	return "govendor"
+}
+
+func (govendorManager) Detect(fs billy.Filesystem) (bool, error) {
+	return fileExists(fs, vendorJSON)
+}
+
+func (govendorManager) Parse(fs billy.Filesystem) ([]Dependency, error) {
+	vf, err := readVendorFile(fs)
 	if err != nil {
-		return nil, "", errors.Wrapf(err, "unable to unmarshal govendor vendorfile")
+		return nil, err
 	}
 
 	deps := make([]Dependency, 0, len(vf.Package))
@@ -33,14 +36,92 @@ func tryGovendor(tree *git.Worktree) ([]Dependency, string, error) {
 			continue
 		}
 
-		deps = append(deps, Dependency{
+		dep := Dependency{
 			Name:     pkg.Path,
 			Revision: pkg.Revision,
 			Source:   pkg.Origin,
-			// pkg.Version
-			// pkg.VersionExact
-		})
+			Version:  pkg.VersionExact,
+		}
+
+		if pkg.Version != "" {
+			constraint, ok := govendorConstraint(pkg.Version)
+			if ok {
+				dep.Constraint = constraint
+			} else {
+				// govendor versions that are not semver are branch names
+				dep.Branch = pkg.Version
+			}
+		}
+
+		deps = append(deps, dep)
 	}
 
-	return deps, "govendor", nil
+	return deps, nil
+}
+
+func (govendorManager) ApplyUpdate(fs billy.Filesystem, dependency, revision, version string) error {
+	vf, err := readVendorFile(fs)
+	if err != nil {
+		return err
+	}
+
+	for _, pkg := range vf.Package {
+		if pkg == nil || !matchesDependency(pkg.Path, dependency) {
+			continue
+		}
+
+		pkg.Revision = revision
+		pkg.VersionExact = version
+		// govendor recalculates these on the next sync
+		pkg.RevisionTime = ""
+		pkg.ChecksumSHA1 = ""
+	}
+
+	buf := &bytes.Buffer{}
+	err = vf.Marshal(buf)
+	if err != nil {
+		return errors.Wrapf(err, "unable to marshal govendor vendorfile")
+	}
+
+	return writeFile(fs, vendorJSON, buf.Bytes())
+}
+
+func readVendorFile(fs billy.Filesystem) (*vendorfile.File, error) {
+	f, err := fs.Open(vendorJSON)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open file govendor vendor file %s", vendorJSON)
+	}
+	defer f.Close()
+
+	vf := &vendorfile.File{}
+	err = vf.Unmarshal(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to unmarshal govendor vendorfile")
+	}
+
+	return vf, nil
+}
+
+// govendorConstraint converts a govendor version, which matches tags by prefix, in to a semver range.
+// "v1" becomes ">= 1.0.0, < 2.0.0", "v1.2" becomes ">= 1.2.0, < 1.3.0" and "v1.2.3" is exact.
+func govendorConstraint(version string) (string, bool) {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	nums := make([]int, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return "", false
+		}
+		nums = append(nums, n)
+	}
+
+	switch len(nums) {
+	case 1:
+		return fmt.Sprintf(">= %d.0.0, < %d.0.0", nums[0], nums[0]+1), true
+	case 2:
+		return fmt.Sprintf(">= %d.%d.0, < %d.%d.0", nums[0], nums[1], nums[0], nums[1]+1), true
+	case 3:
+		return fmt.Sprintf("= %d.%d.%d", nums[0], nums[1], nums[2]), true
+	}
+	return "", false
 }
@@ -0,0 +1,54 @@
+package depmap
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGovendorManager(t *testing.T) {
+	assert := require.New(t)
+
+	fs := fixtureFS(t, "govendor")
+	used, err := govendorManager{}.Detect(fs)
+	assert.NoError(err)
+	assert.True(used)
+
+	deps, err := govendorManager{}.Parse(fs)
+	assert.NoError(err)
+	assert.Equal([]Dependency{
+		{Name: "github.com/pkg/errors", Revision: "645ef00459ed84a119197bfb8d8205042c6df63d", Version: "v0.8.0", Constraint: ">= 0.8.0, < 0.9.0"},
+		{Name: "golang.org/x/oauth2", Revision: "ef147856a6ddbb60760db74283d2424e98c87bff", Source: "github.com/example/oauth2"},
+	}, deps)
+}
+
+func TestGovendorManager_NotUsed(t *testing.T) {
+	assert := require.New(t)
+
+	used, err := govendorManager{}.Detect(fixtureFS(t, "empty"))
+	assert.NoError(err)
+	assert.False(used)
+}
+
+func TestGovendorConstraint(t *testing.T) {
+	for i, c := range []struct {
+		expected string
+		ok       bool
+		version  string
+	}{
+		{">= 1.0.0, < 2.0.0", true, "v1"},
+		{">= 1.2.0, < 1.3.0", true, "v1.2"},
+		{"= 1.2.3", true, "v1.2.3"},
+		{">= 0.8.0, < 0.9.0", true, "0.8"},
+		{"", false, "master"},
+		{"", false, "v1.2.3.4"},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			actual, ok := govendorConstraint(c.version)
+			assert.Equal(t, c.ok, ok)
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package depmap
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	billy "gopkg.in/src-d/go-billy.v4"
+)
+
+// ErrUpdateNotSupported is returned by ApplyUpdate when a dependency manager can not rewrite its manifest.
+var ErrUpdateNotSupported = errors.New("dependency manager does not support updates")
+
+var errFileNotFound = errors.New("file not found")
+
+// DependencyManager represents a Go dependency management tool and its manifest format.
+type DependencyManager interface {
+	// Name is the dependency type reported for projects using the manager, for example "govendor".
+	Name() string
+
+	// Detect reports if the manager is in use in the file system.
+	Detect(fs billy.Filesystem) (bool, error)
+
+	// Parse loads all the dependencies from the manifest in the file system.
+	Parse(fs billy.Filesystem) ([]Dependency, error)
+}
+
+// ManifestUpdater is optionally implemented by a DependencyManager that can rewrite its own manifest.
+type ManifestUpdater interface {
+	// ApplyUpdate points every package of the dependency at the new revision and version.
+	ApplyUpdate(fs billy.Filesystem, dependency, revision, version string) error
+}
+
+var (
+	managersMu sync.RWMutex
+	managers   = []DependencyManager{
+		govendorManager{},
+		gomodManager{},
+		depManager{},
+		glideManager{},
+		godepManager{},
+	}
+)
+
+// Register makes a dependency manager available to Project.Dependencies. Managers are
+// tested in registration order, after the built in ones. Register panics if a manager
+// with the same name is already registered.
+func Register(dm DependencyManager) {
+	managersMu.Lock()
+	defer managersMu.Unlock()
+
+	if dm == nil {
+		panic("depmap: Register dependency manager is nil")
+	}
+	for _, existing := range managers {
+		if existing.Name() == dm.Name() {
+			panic("depmap: Register called twice for dependency manager " + dm.Name())
+		}
+	}
+	managers = append(managers, dm)
+}
+
+// Managers returns all the registered dependency managers in the order they are tested.
+func Managers() []DependencyManager {
+	managersMu.RLock()
+	defer managersMu.RUnlock()
+
+	return append([]DependencyManager(nil), managers...)
+}
+
+// Manager returns the registered dependency manager with the given name, or nil.
+func Manager(name string) DependencyManager {
+	for _, dm := range Managers() {
+		if dm.Name() == name {
+			return dm
+		}
+	}
+	return nil
+}
+
+// ApplyUpdate asks the dependency manager to rewrite its manifest for a new version of a dependency.
+func ApplyUpdate(dm DependencyManager, fs billy.Filesystem, dependency, revision, version string) error {
+	mu, ok := dm.(ManifestUpdater)
+	if !ok {
+		return ErrUpdateNotSupported
+	}
+	return mu.ApplyUpdate(fs, dependency, revision, version)
+}
+
+// fileExists reports if the file is present in the file system.
+func fileExists(fs billy.Filesystem, name string) (bool, error) {
+	_, err := fs.Stat(name)
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// readFile reads an entire file from the file system, returning errFileNotFound if it is missing.
+func readFile(fs billy.Filesystem, name string) ([]byte, error) {
+	f, err := fs.Open(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errFileNotFound
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	return ioutil.ReadAll(f)
+}
+
+// writeFile replaces the contents of a file in the file system.
+func writeFile(fs billy.Filesystem, name string, data []byte) error {
+	f, err := fs.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// matchesDependency reports if a package path belongs to the dependency.
+func matchesDependency(pkg, dependency string) bool {
+	return pkg == dependency || strings.HasPrefix(pkg, dependency+"/")
+}
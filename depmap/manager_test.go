@@ -0,0 +1,61 @@
+package depmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	billy "gopkg.in/src-d/go-billy.v4"
+)
+
+type testManager struct{}
+
+func (testManager) Name() string                                    { return "test" }
+func (testManager) Detect(fs billy.Filesystem) (bool, error)        { return fileExists(fs, "deps.txt") }
+func (testManager) Parse(fs billy.Filesystem) ([]Dependency, error) { return nil, nil }
+
+func TestRegister(t *testing.T) {
+	assert := require.New(t)
+
+	defer func(orig []DependencyManager) { managers = orig }(Managers())
+
+	Register(testManager{})
+
+	all := Managers()
+	assert.Equal(testManager{}, all[len(all)-1])
+	assert.Equal(testManager{}, Manager("test"))
+	assert.Equal(govendorManager{}, Manager("govendor"))
+	assert.Nil(Manager("unknown"))
+
+	assert.Panics(func() { Register(testManager{}) })
+
+	err := ApplyUpdate(Manager("test"), fixtureFS(t, "empty"), "github.com/pkg/errors", "abc", "v1.0.0")
+	assert.Equal(ErrUpdateNotSupported, err)
+}
+
+func TestApplyUpdate(t *testing.T) {
+	for _, dm := range []DependencyManager{govendorManager{}, gomodManager{}, godepManager{}} {
+		t.Run(dm.Name(), func(t *testing.T) {
+			assert := require.New(t)
+
+			fs := fixtureFS(t, dm.Name())
+			assert.NoError(ApplyUpdate(dm, fs, "github.com/pkg/errors", "816c9085562cd7ee03e7f8188a1cfd942858cded", "v0.8.1"))
+
+			deps, err := dm.Parse(fs)
+			assert.NoError(err)
+
+			found := false
+			for _, d := range deps {
+				if d.Name != "github.com/pkg/errors" {
+					continue
+				}
+				found = true
+				if dm.Name() == "gomod" {
+					assert.Equal("v0.8.1", d.Revision)
+				} else {
+					assert.Equal("816c9085562cd7ee03e7f8188a1cfd942858cded", d.Revision)
+				}
+			}
+			assert.True(found)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package depmap
+
+import (
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	billy "gopkg.in/src-d/go-billy.v4"
+)
+
+// Manifest represents the dependencies declared by one dependency manager in a directory of a project.
+type Manifest struct {
+	// Directory containing the manifest, relative to the root of the repository.
+	Path string
+
+	// Name of the dependency manager, for example "govendor".
+	Type string
+
+	Dependencies []Dependency
+}
+
+// FindManifests walks the file system and loads every manifest of a registered dependency manager.
+func FindManifests(fs billy.Filesystem) ([]Manifest, error) {
+	manifests := []Manifest{}
+	err := findManifests(fs, ".", &manifests)
+	if err != nil {
+		return nil, err
+	}
+	return manifests, nil
+}
+
+func findManifests(fs billy.Filesystem, dir string, manifests *[]Manifest) error {
+	sub := fs
+	if dir != "." {
+		var err error
+		sub, err = fs.Chroot(dir)
+		if err != nil {
+			return errors.Wrapf(err, "unable to chroot to %s", dir)
+		}
+	}
+
+	for _, dm := range Managers() {
+		used, err := dm.Detect(sub)
+		if err != nil {
+			return errors.Wrapf(err, "error testing for dep manager %s in %s", dm.Name(), dir)
+		}
+		if !used {
+			continue
+		}
+
+		deps, err := dm.Parse(sub)
+		if err != nil {
+			return errors.Wrapf(err, "error loading dependencies with %s in %s", dm.Name(), dir)
+		}
+		for i := range deps {
+			deps[i].Manifest = dir
+			deps[i].Manager = dm.Name()
+		}
+
+		*manifests = append(*manifests, Manifest{
+			Path:         dir,
+			Type:         dm.Name(),
+			Dependencies: deps,
+		})
+	}
+
+	infos, err := fs.ReadDir(dir)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read directory %s", dir)
+	}
+
+	for _, info := range infos {
+		if !info.IsDir() || skipDir(info.Name()) {
+			continue
+		}
+
+		err = findManifests(fs, path.Join(dir, info.Name()), manifests)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// skipDir reports if a directory can not contain a project's own manifests, using
+// the same rules as the go tool plus the directories used to store vendored code.
+func skipDir(name string) bool {
+	switch name {
+	case "vendor", "testdata", "Godeps":
+		return true
+	}
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
@@ -0,0 +1,38 @@
+package depmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindManifests(t *testing.T) {
+	assert := require.New(t)
+
+	manifests, err := FindManifests(fixtureFS(t, "monorepo"))
+	assert.NoError(err)
+
+	actual := map[string]string{}
+	for _, m := range manifests {
+		actual[m.Path] = m.Type
+		assert.NotEmpty(m.Dependencies)
+		for _, d := range m.Dependencies {
+			assert.Equal(m.Path, d.Manifest)
+			assert.Equal(m.Type, d.Manager)
+		}
+	}
+
+	assert.Equal(map[string]string{
+		".":            "gomod",
+		"services/api": "govendor",
+		"tools":        "godep",
+	}, actual)
+}
+
+func TestFindManifests_None(t *testing.T) {
+	assert := require.New(t)
+
+	manifests, err := FindManifests(fixtureFS(t, "empty"))
+	assert.NoError(err)
+	assert.Empty(manifests)
+}
@@ -2,22 +2,29 @@ package depmap
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/pkg/errors"
 	"gopkg.in/src-d/go-billy.v4/memfs"
 	git "gopkg.in/src-d/go-git.v4"
-	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/storage/memory"
 )
 
-var errDepSystemNotUsed = errors.New("dependency system not used")
-
 // Project represents a single repository that needs updates.
 type Project struct {
 	Name   string
 	GitURL string
 	Branch string
+
+	// Config is the repository's own configuration, loaded with its dependencies.
+	Config *Config `json:",omitempty"`
+}
+
+// TargetBranch is the branch PRs for the project are submitted to.
+func (r *Project) TargetBranch() string {
+	if r.Config != nil && r.Config.TargetBranch != "" {
+		return r.Config.TargetBranch
+	}
+	return r.Branch
 }
 
 // Dependency represents other packages a project depends on, and the current revision.
@@ -30,39 +37,125 @@ type Dependency struct {
 
 	// Optional. Alternative source, or fork, for the project.
 	Source string
+
+	// Optional. Version as declared in the manifest, including pseudo-versions.
+	Version string
+
+	// Optional. Dependency is not imported directly by the project.
+	Indirect bool
+
+	// Optional. Versions the manifest excludes from selection.
+	Excluded []string
+
+	// Optional. Branch the dependency is locked to.
+	Branch string
+
+	// Optional. Version constraint declared in the manifest, for example "^1.2.0".
+	Constraint string
+
+	// Optional. Directory of the manifest declaring the dependency, relative to the repository root.
+	Manifest string
+
+	// Optional. Name of the dependency manager of the manifest, for example "govendor".
+	Manager string `json:",omitempty"`
+
+	// Optional. How the project's code uses the dependency.
+	Usage Usage `json:",omitempty"`
+
+	// Optional. Package-level identifiers of the dependency the project's code refers to,
+	// as "<import path>.<identifier>".
+	References []string `json:",omitempty"`
+
+	// Optional. Dependency is a Go module requirement, its major versions from v2 on are
+	// separate import paths.
+	Module bool `json:",omitempty"`
 }
 
-var depManagers = []func(*git.Worktree) ([]Dependency, string, error){
-	tryGovendor,
+// LoadOptions controls how a project is retrieved to load its dependencies.
+type LoadOptions struct {
+	// Optional. Repository cache to use instead of an in memory clone.
+	Cache *Cache
+
+	// Optional. Credentials for private repositories.
+	Credentials *Credentials
 }
 
-// Dependencies will load all of the dependencies out of the current version of the project.
-func (r *Project) Dependencies(ctx context.Context) ([]Dependency, string, error) {
-	repo, err := git.CloneContext(ctx, memory.NewStorage(), memfs.New(), &git.CloneOptions{
-		URL:           r.GitURL,
-		ReferenceName: plumbing.ReferenceName(fmt.Sprintf("refs/heads/%s", r.Branch)),
-		SingleBranch:  true,
-		Depth:         1,
-	})
+// Dependencies will load all of the dependencies out of the current version of the project,
+// grouped by the manifest that declares them, and the project's Config. opts may be nil. Credential failures
+// are returned as an *AuthError, use errors.Cause to retrieve it.
+func (r *Project) Dependencies(ctx context.Context, opts *LoadOptions) ([]Manifest, error) {
+	if opts == nil {
+		opts = &LoadOptions{}
+	}
+
+	repo, err := r.clone(ctx, opts)
 	if err != nil {
-		return nil, "", errors.Wrapf(err, "unable to clone repository %s", r.GitURL)
+		return nil, err
+	}
+	if opts.Cache != nil {
+		defer opts.Cache.Release(r.GitURL)
 	}
 
 	tree, err := repo.Worktree()
 	if err != nil {
-		return nil, "", errors.Wrapf(err, "unable to load work tree")
+		return nil, errors.Wrapf(err, "unable to load work tree")
 	}
 
-	for _, dm := range depManagers {
-		deps, deptype, err := dm(tree)
-		if err == errDepSystemNotUsed {
-			continue
+	r.Config, err = LoadConfig(tree.Filesystem)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests, err := FindManifests(tree.Filesystem)
+	if err != nil {
+		return nil, err
+	}
+	if len(manifests) == 0 {
+		return nil, errors.New("no dependency management found")
+	}
+
+	for i := range manifests {
+		// nested manifests are separate projects as far as imports are concerned
+		skip := []string{}
+		for _, other := range manifests {
+			if other.Path != manifests[i].Path {
+				skip = append(skip, other.Path)
+			}
 		}
+
+		err = ClassifyUsage(tree.Filesystem, &manifests[i], skip)
 		if err != nil {
-			return nil, "", errors.Wrapf(err, "error testing for dep manager")
+			return nil, err
 		}
-		return deps, deptype, nil
+		err = CollectReferences(tree.Filesystem, &manifests[i], skip)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return manifests, nil
+}
+
+func (r *Project) clone(ctx context.Context, opts *LoadOptions) (*git.Repository, error) {
+	auth, err := opts.Credentials.AuthMethod(r.GitURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Cache != nil {
+		return opts.Cache.Open(ctx, r.GitURL, r.Branch, auth)
 	}
 
-	return nil, "", errors.New("no dependency management found")
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), memfs.New(), &git.CloneOptions{
+		URL:           r.GitURL,
+		Auth:          auth,
+		ReferenceName: BranchReference(r.Branch),
+		// go-git assumes a single branch cloned from HEAD is master
+		SingleBranch: r.Branch != "",
+		Depth:        1,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(wrapAuthError(r.GitURL, err), "unable to clone repository %s", r.GitURL)
+	}
+	return repo, nil
 }
@@ -0,0 +1,48 @@
+package depmap
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	billy "gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+)
+
+// fixtureFS copies a directory from testdata in to an in memory file system.
+func fixtureFS(t *testing.T, fixture string) billy.Filesystem {
+	assert := require.New(t)
+
+	fs := memfs.New()
+	root := filepath.Join("testdata", fixture)
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		f, err := fs.Create(filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = f.Write(raw)
+		return err
+	})
+	assert.NoError(err)
+
+	return fs
+}
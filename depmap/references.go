@@ -0,0 +1,144 @@
+package depmap
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	billy "gopkg.in/src-d/go-billy.v4"
+)
+
+// CollectReferences sets the References of every dependency in the manifest to the package-level
+// identifiers of the dependency the Go sources below the manifest's directory refer to, tests
+// included. Directories listed in skip are not considered part of the project.
+func CollectReferences(fs billy.Filesystem, m *Manifest, skip []string) error {
+	skipped := map[string]bool{}
+	for _, s := range skip {
+		skipped[s] = true
+	}
+
+	refs := map[string]bool{}
+	err := walkReferences(fs, m.Path, skipped, refs)
+	if err != nil {
+		return err
+	}
+
+	byDep := map[int][]string{}
+	for ref := range refs {
+		imp := ref[:strings.LastIndex(ref, ".")]
+		if i, ok := matchImport(m.Dependencies, imp); ok {
+			byDep[i] = append(byDep[i], ref)
+		}
+	}
+	for i := range m.Dependencies {
+		sort.Strings(byDep[i])
+		m.Dependencies[i].References = byDep[i]
+	}
+
+	return nil
+}
+
+func walkReferences(fs billy.Filesystem, dir string, skip map[string]bool, refs map[string]bool) error {
+	infos, err := fs.ReadDir(dir)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read directory %s", dir)
+	}
+
+	fset := token.NewFileSet()
+	for _, info := range infos {
+		name := info.Name()
+		sub := path.Join(dir, name)
+		if info.IsDir() {
+			if skipDir(name) || skip[sub] {
+				continue
+			}
+			err = walkReferences(fs, sub, skip, refs)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if !strings.HasSuffix(name, ".go") {
+			continue
+		}
+
+		src, err := readFile(fs, sub)
+		if err != nil {
+			return errors.Wrapf(err, "unable to read %s", sub)
+		}
+		f, err := parser.ParseFile(fset, sub, src, 0)
+		if err != nil {
+			// broken or generated template sources should not fail the scan
+			continue
+		}
+		fileReferences(f, refs)
+	}
+
+	return nil
+}
+
+// fileReferences adds the qualified identifiers of a file, as "<import path>.<identifier>", to refs.
+func fileReferences(f *ast.File, refs map[string]bool) {
+	imports := map[string]string{}
+	for _, spec := range f.Imports {
+		imp, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if spec.Name != nil {
+			imports[spec.Name.Name] = imp
+			continue
+		}
+		for _, name := range ImportNames(imp) {
+			imports[name] = imp
+		}
+	}
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		x, ok := sel.X.(*ast.Ident)
+		if !ok || x.Obj != nil {
+			// declared in the file, not a package name
+			return true
+		}
+		if imp, ok := imports[x.Name]; ok && ast.IsExported(sel.Sel.Name) {
+			refs[imp+"."+sel.Sel.Name] = true
+		}
+		return true
+	})
+}
+
+var (
+	majorSuffix   = regexp.MustCompile(`^v[0-9]+$`)
+	versionSuffix = regexp.MustCompile(`\.v[0-9]+$`)
+)
+
+// ImportNames returns the likely names of the package at the import path, which are only known for
+// certain from its sources. For example "gopkg.in/yaml.v2" is likely "yaml" and
+// "github.com/foo/go-bar/v2" "bar" or "go-bar".
+func ImportNames(imp string) []string {
+	elems := strings.Split(imp, "/")
+	last := elems[len(elems)-1]
+	if majorSuffix.MatchString(last) && len(elems) > 1 {
+		last = elems[len(elems)-2]
+	}
+	last = versionSuffix.ReplaceAllString(last, "")
+
+	names := []string{last}
+	trimmed := strings.TrimSuffix(strings.TrimPrefix(last, "go-"), "-go")
+	trimmed = strings.Replace(trimmed, "-", "", -1)
+	trimmed = strings.Replace(trimmed, ".", "", -1)
+	if trimmed != last {
+		names = append(names, trimmed)
+	}
+	return names
+}
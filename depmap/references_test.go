@@ -0,0 +1,45 @@
+package depmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectReferences(t *testing.T) {
+	assert := require.New(t)
+
+	fs := fixtureFS(t, "usage")
+	manifests, err := FindManifests(fs)
+	assert.NoError(err)
+	assert.Len(manifests, 1)
+
+	assert.NoError(CollectReferences(fs, &manifests[0], nil))
+
+	actual := map[string][]string{}
+	for _, d := range manifests[0].Dependencies {
+		actual[d.Name] = d.References
+	}
+	assert.Equal(map[string][]string{
+		"github.com/davecgh/go-spew/spew":    nil,
+		"github.com/pkg/errors":              {"github.com/pkg/errors.New"},
+		"github.com/stretchr/testify/assert": {"github.com/stretchr/testify/assert.Error"},
+		"golang.org/x/oauth2":                nil,
+	}, actual)
+}
+
+func TestImportNames(t *testing.T) {
+	for imp, expected := range map[string][]string{
+		"github.com/pkg/errors":      {"errors"},
+		"gopkg.in/yaml.v2":           {"yaml"},
+		"github.com/foo/go-bar/v2":   {"go-bar", "bar"},
+		"github.com/foo/bar-go":      {"bar-go", "bar"},
+		"github.com/mitchellh/cli":   {"cli"},
+		"gopkg.in/src-d/go-git.v4":   {"go-git", "git"},
+		"github.com/boz/go-throttle": {"go-throttle", "throttle"},
+	} {
+		t.Run(imp, func(t *testing.T) {
+			require.Equal(t, expected, ImportNames(imp))
+		})
+	}
+}
@@ -0,0 +1,21 @@
+ignore = ["github.com/aws/aws-sdk-go"]
+
+pin {
+  "github.com/pkg/errors" = "~0.8.0"
+}
+
+prereleases {
+  "github.com/foo/bar" = ["rc"]
+}
+
+track {
+  "github.com/foo/baz" = "branch"
+}
+
+allow          = ["patch", "minor"]
+deny_licenses  = ["AGPL-3.0", "copyleft"]
+group          = "prefix"
+group_prefixes = ["github.com/aws/"]
+target_branch  = "develop"
+reviewers      = ["octocat"]
+labels         = ["dependencies"]
@@ -0,0 +1,12 @@
+host "git.example.com" {
+  username = "bot"
+  password = "secret"
+}
+
+host "gitlab.com" {
+  token = "abc123"
+}
+
+host "ssh.example.com" {
+  ssh_key_file = "testdata/missing_key"
+}
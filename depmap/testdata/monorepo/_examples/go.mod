@@ -0,0 +1,3 @@
+module example.com/foo/bar/_examples
+
+require github.com/pkg/errors v0.8.0
@@ -0,0 +1,15 @@
+module example.com/foo/bar
+
+go 1.12
+
+require (
+	github.com/pkg/errors v0.8.0
+	github.com/stretchr/testify v1.2.2 // indirect
+	golang.org/x/oauth2 v0.0.0-20180620175406-ef147856a6dd
+	gopkg.in/src-d/go-git.v4 v4.4.1
+	github.com/google/go-github v15.0.0+incompatible
+)
+
+replace gopkg.in/src-d/go-git.v4 => github.com/example/go-git v4.4.2-fork
+
+exclude github.com/pkg/errors v0.8.1
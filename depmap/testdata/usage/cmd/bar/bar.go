@@ -0,0 +1,7 @@
+package bar
+
+import "github.com/pkg/errors"
+
+func Run() error {
+	return errors.New("not implemented")
+}
@@ -0,0 +1,11 @@
+package bar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	assert.Error(t, Run())
+}
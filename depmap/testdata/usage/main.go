@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/foo/bar/cmd/bar"
+)
+
+func main() {
+	fmt.Println(bar.Run())
+}
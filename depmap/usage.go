@@ -0,0 +1,211 @@
+package depmap
+
+import (
+	"go/parser"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	billy "gopkg.in/src-d/go-billy.v4"
+)
+
+// Usage describes how a project's code uses a dependency.
+type Usage string
+
+// Dependency usages, determined from the imports of the project's Go sources.
+const (
+	// UsageDirect dependencies are imported by the project's non-test code.
+	UsageDirect Usage = "direct"
+	// UsageTest dependencies are only imported by the project's tests.
+	UsageTest Usage = "test"
+	// UsageTransitive dependencies are only imported by other dependencies.
+	UsageTransitive Usage = "transitive"
+	// UsageUnused dependencies are not imported at all.
+	UsageUnused Usage = "unused"
+	// UsageUnknown dependencies are not imported by the project, but may be imported by other
+	// dependencies that are not vendored.
+	UsageUnknown Usage = "unknown"
+)
+
+// ClassifyUsage sets the Usage of every dependency in the manifest by parsing the imports of the Go
+// sources below the manifest's directory, and of vendored packages when they are present. Directories
+// listed in skip, such as those of other manifests, are not considered part of the project. Without
+// vendored packages, dependencies the project does not import are only reported unused when the
+// manifest marks its indirect dependencies, as go.mod does.
+func ClassifyUsage(fs billy.Filesystem, m *Manifest, skip []string) error {
+	skipped := map[string]bool{}
+	for _, s := range skip {
+		skipped[s] = true
+	}
+
+	imports, testImports := map[string]bool{}, map[string]bool{}
+	err := walkImports(fs, m.Path, skipped, imports, testImports)
+	if err != nil {
+		return err
+	}
+
+	// follow imports through vendored sources to find transitive dependencies
+	vendorDir := path.Join(m.Path, "vendor")
+	vendored, err := fileExists(fs, vendorDir)
+	if err != nil {
+		return err
+	}
+	transitive := map[string]bool{}
+	queue := []string{}
+	for imp := range imports {
+		queue = append(queue, imp)
+	}
+	for imp := range testImports {
+		queue = append(queue, imp)
+	}
+	visited := map[string]bool{}
+	for len(queue) > 0 {
+		imp := queue[0]
+		queue = queue[1:]
+		if visited[imp] {
+			continue
+		}
+		visited[imp] = true
+
+		pkgImports, _, err := parseImports(fs, path.Join(vendorDir, imp))
+		if err != nil {
+			return err
+		}
+		for p := range pkgImports {
+			transitive[p] = true
+			queue = append(queue, p)
+		}
+	}
+
+	direct, test, indirect := map[int]bool{}, map[int]bool{}, map[int]bool{}
+	for imp := range imports {
+		if i, ok := matchImport(m.Dependencies, imp); ok {
+			direct[i] = true
+		}
+	}
+	for imp := range testImports {
+		if i, ok := matchImport(m.Dependencies, imp); ok {
+			test[i] = true
+		}
+	}
+	for imp := range transitive {
+		if i, ok := matchImport(m.Dependencies, imp); ok {
+			indirect[i] = true
+		}
+	}
+
+	for i := range m.Dependencies {
+		d := &m.Dependencies[i]
+		switch {
+		case direct[i]:
+			d.Usage = UsageDirect
+		case test[i]:
+			d.Usage = UsageTest
+		case indirect[i], d.Indirect:
+			d.Usage = UsageTransitive
+		case !vendored && m.Type != "gomod":
+			// the lock lists every transitive dependency, without their sources to tell
+			d.Usage = UsageUnknown
+		default:
+			d.Usage = UsageUnused
+		}
+	}
+
+	return nil
+}
+
+// matchImport returns the index of the dependency providing the import path, preferring the most
+// specific dependency when both a project and its packages are listed.
+func matchImport(deps []Dependency, imp string) (int, bool) {
+	best, found := -1, false
+	for i, d := range deps {
+		if !matchesDependency(imp, d.Name) {
+			continue
+		}
+		if !found || len(d.Name) > len(deps[best].Name) {
+			best, found = i, true
+		}
+	}
+	return best, found
+}
+
+func walkImports(fs billy.Filesystem, dir string, skip map[string]bool, imports, testImports map[string]bool) error {
+	pkgImports, pkgTestImports, err := parseImports(fs, dir)
+	if err != nil {
+		return err
+	}
+	for imp := range pkgImports {
+		imports[imp] = true
+	}
+	for imp := range pkgTestImports {
+		testImports[imp] = true
+	}
+
+	infos, err := fs.ReadDir(dir)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read directory %s", dir)
+	}
+	for _, info := range infos {
+		sub := path.Join(dir, info.Name())
+		if !info.IsDir() || skipDir(info.Name()) || skip[sub] {
+			continue
+		}
+		err = walkImports(fs, sub, skip, imports, testImports)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseImports returns the imports of the Go files in a single directory, split between regular and
+// test files. A missing directory has no imports.
+func parseImports(fs billy.Filesystem, dir string) (map[string]bool, map[string]bool, error) {
+	imports, testImports := map[string]bool{}, map[string]bool{}
+
+	infos, err := fs.ReadDir(dir)
+	if err != nil {
+		exists, statErr := fileExists(fs, dir)
+		if statErr == nil && !exists {
+			return imports, testImports, nil
+		}
+		return nil, nil, errors.Wrapf(err, "unable to read directory %s", dir)
+	}
+
+	fset := token.NewFileSet()
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !strings.HasSuffix(name, ".go") {
+			continue
+		}
+
+		filename := path.Join(dir, name)
+		src, err := readFile(fs, filename)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "unable to read %s", filename)
+		}
+
+		f, err := parser.ParseFile(fset, filename, src, parser.ImportsOnly)
+		if err != nil {
+			// broken or generated template sources should not fail the scan
+			continue
+		}
+
+		target := imports
+		if strings.HasSuffix(name, "_test.go") {
+			target = testImports
+		}
+		for _, spec := range f.Imports {
+			imp, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			target[imp] = true
+		}
+	}
+
+	return imports, testImports, nil
+}
@@ -0,0 +1,81 @@
+package depmap
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+func TestClassifyUsage(t *testing.T) {
+	assert := require.New(t)
+
+	fs := fixtureFS(t, "usage")
+	manifests, err := FindManifests(fs)
+	assert.NoError(err)
+	assert.Len(manifests, 1)
+
+	assert.NoError(ClassifyUsage(fs, &manifests[0], nil))
+
+	actual := map[string]Usage{}
+	for _, d := range manifests[0].Dependencies {
+		actual[d.Name] = d.Usage
+	}
+	assert.Equal(map[string]Usage{
+		"github.com/davecgh/go-spew/spew":    UsageTransitive,
+		"github.com/pkg/errors":              UsageDirect,
+		"github.com/stretchr/testify/assert": UsageTest,
+		"golang.org/x/oauth2":                UsageUnused,
+	}, actual)
+}
+
+func TestClassifyUsage_Unvendored(t *testing.T) {
+	for i, c := range []struct {
+		manager  string
+		expected Usage
+	}{
+		{"dep", UsageUnknown},
+		{"glide", UsageUnknown},
+		{"gomod", UsageUnused},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert := require.New(t)
+
+			fs := memfs.New()
+			assert.NoError(util.WriteFile(fs, "main.go", []byte("package main\n\nimport _ \"github.com/pkg/errors\"\n"), 0644))
+
+			m := &Manifest{Path: ".", Type: c.manager, Dependencies: []Dependency{
+				{Name: "github.com/pkg/errors"},
+				{Name: "github.com/pkg/indirect", Indirect: true},
+				{Name: "golang.org/x/oauth2"},
+			}}
+			assert.NoError(ClassifyUsage(fs, m, nil))
+
+			assert.Equal(UsageDirect, m.Dependencies[0].Usage)
+			assert.Equal(UsageTransitive, m.Dependencies[1].Usage)
+			assert.Equal(c.expected, m.Dependencies[2].Usage)
+		})
+	}
+}
+
+func TestMatchImport(t *testing.T) {
+	assert := require.New(t)
+
+	deps := []Dependency{
+		{Name: "github.com/foo/bar"},
+		{Name: "github.com/foo/bar/baz"},
+	}
+
+	i, ok := matchImport(deps, "github.com/foo/bar/baz/qux")
+	assert.True(ok)
+	assert.Equal(1, i)
+
+	i, ok = matchImport(deps, "github.com/foo/bar/other")
+	assert.True(ok)
+	assert.Equal(0, i)
+
+	_, ok = matchImport(deps, "github.com/foo/barbaz")
+	assert.False(ok)
+}
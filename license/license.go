@@ -0,0 +1,150 @@
+// Package license detects and classifies the license of a repository.
+package license
+
+import (
+	"bytes"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// SPDX identifiers of the recognized licenses.
+const (
+	MIT        = "MIT"
+	ISC        = "ISC"
+	BSD2Clause = "BSD-2-Clause"
+	BSD3Clause = "BSD-3-Clause"
+	Apache20   = "Apache-2.0"
+	MPL20      = "MPL-2.0"
+	LGPL21     = "LGPL-2.1"
+	LGPL30     = "LGPL-3.0"
+	GPL20      = "GPL-2.0"
+	GPL30      = "GPL-3.0"
+	AGPL30     = "AGPL-3.0"
+	Unlicense  = "Unlicense"
+
+	// Unknown is a license file that could not be classified.
+	Unknown = "unknown"
+	// None is a repository without a license file.
+	None = "none"
+)
+
+// License kinds.
+const (
+	KindPermissive   = "permissive"
+	KindWeakCopyleft = "weak-copyleft"
+	KindCopyleft     = "copyleft"
+)
+
+var kinds = map[string]string{
+	MIT:        KindPermissive,
+	ISC:        KindPermissive,
+	BSD2Clause: KindPermissive,
+	BSD3Clause: KindPermissive,
+	Apache20:   KindPermissive,
+	Unlicense:  KindPermissive,
+	MPL20:      KindWeakCopyleft,
+	LGPL21:     KindWeakCopyleft,
+	LGPL30:     KindWeakCopyleft,
+	GPL20:      KindCopyleft,
+	GPL30:      KindCopyleft,
+	AGPL30:     KindCopyleft,
+}
+
+// Kind returns whether the license is permissive or copyleft, or an empty string if it is not known.
+func Kind(id string) string {
+	return kinds[id]
+}
+
+// files are the names of license files, in order of preference.
+var files = []string{
+	"LICENSE",
+	"LICENSE.md",
+	"LICENSE.txt",
+	"LICENCE",
+	"LICENCE.md",
+	"COPYING",
+	"COPYING.md",
+	"UNLICENSE",
+}
+
+// classifiers match normalized license texts, the more specific licenses first. The GNU licenses
+// refer to each other, so they are matched by their titles.
+var classifiers = []struct {
+	id      string
+	phrases []string
+}{
+	{AGPL30, []string{"gnu affero general public license version 3"}},
+	{LGPL30, []string{"gnu lesser general public license version 3"}},
+	{LGPL21, []string{"gnu lesser general public license version 2.1"}},
+	{GPL30, []string{"gnu general public license version 3"}},
+	{GPL20, []string{"gnu general public license version 2"}},
+	{MPL20, []string{"mozilla public license", "2.0"}},
+	{Apache20, []string{"apache license", "version 2.0"}},
+	{BSD3Clause, []string{"redistribution and use in source and binary forms", "neither the name"}},
+	{BSD2Clause, []string{"redistribution and use in source and binary forms"}},
+	{ISC, []string{"permission to use, copy, modify, and/or distribute this software for any purpose"}},
+	{MIT, []string{"permission is hereby granted, free of charge"}},
+	{Unlicense, []string{"this is free and unencumbered software released into the public domain"}},
+}
+
+var space = regexp.MustCompile(`\s+`)
+
+// Classify returns the SPDX identifier of a license text, or Unknown.
+func Classify(text []byte) string {
+	normalized := strings.ToLower(space.ReplaceAllString(string(bytes.TrimSpace(text)), " "))
+
+	for _, c := range classifiers {
+		matches := true
+		for _, phrase := range c.phrases {
+			if !strings.Contains(normalized, phrase) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return c.id
+		}
+	}
+	return Unknown
+}
+
+// FromTree classifies the license file in the root of a git tree, it returns None when there is
+// no license file.
+func FromTree(tree *object.Tree) (string, error) {
+	for _, name := range files {
+		f, err := tree.File(name)
+		if err == object.ErrFileNotFound {
+			continue
+		}
+		if err != nil {
+			return "", errors.Wrapf(err, "unable to open %s", name)
+		}
+
+		r, err := f.Reader()
+		if err != nil {
+			return "", errors.Wrapf(err, "unable to read %s", name)
+		}
+		text, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return "", errors.Wrapf(err, "unable to read %s", name)
+		}
+		return Classify(text), nil
+	}
+	return None, nil
+}
+
+// Denied reports if the license is on the deny list. Entries are SPDX identifiers or license
+// kinds, such as "copyleft".
+func Denied(deny []string, id string) bool {
+	for _, d := range deny {
+		if strings.EqualFold(d, id) || (Kind(id) != "" && d == Kind(id)) {
+			return true
+		}
+	}
+	return false
+}
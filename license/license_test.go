@@ -0,0 +1,52 @@
+package license
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	for i, c := range []struct {
+		expected string
+		file     string
+	}{
+		{MIT, "MIT"},
+		{Apache20, "Apache-2.0"},
+		{BSD3Clause, "BSD-3-Clause"},
+		{GPL30, "GPL-3.0"},
+		{Unknown, "custom"},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert := require.New(t)
+
+			text, err := ioutil.ReadFile(filepath.Join("testdata", c.file))
+			assert.NoError(err)
+			assert.Equal(c.expected, Classify(text))
+		})
+	}
+}
+
+func TestDenied(t *testing.T) {
+	for i, c := range []struct {
+		expected bool
+		deny     []string
+		id       string
+	}{
+		{true, []string{"GPL-3.0"}, GPL30},
+		{true, []string{"agpl-3.0"}, AGPL30},
+		{true, []string{KindCopyleft}, GPL20},
+		{false, []string{KindCopyleft}, LGPL21},
+		{false, []string{KindCopyleft}, MIT},
+		{false, []string{KindCopyleft}, Unknown},
+		{true, []string{Unknown}, Unknown},
+		{false, nil, GPL30},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			require.Equal(t, c.expected, Denied(c.deny, c.id))
+		})
+	}
+}
@@ -25,10 +25,16 @@ func main() {
 	c.Commands = map[string]cli.CommandFactory{
 		"pr submit": cmd.PRSubmitCommandFactory(ui),
 
+		"changelog show": cmd.ChangelogShowCommandFactory(ui),
+
 		"project register": cmd.ProjectRegisterCommandFactory(ui),
 
 		"github listen": cmd.GithubListenCommandFactory(ui),
 		"github watch":  cmd.GithubWatchCommandFactory(ui),
+
+		"updates list": cmd.UpdatesListCommandFactory(ui),
+
+		"vuln scan": cmd.VulnScanCommandFactory(ui),
 	}
 
 	exitStatus, err := c.Run()
@@ -0,0 +1,84 @@
+package updater
+
+import (
+	"strings"
+
+	"github.com/golang/dep/gps"
+	"github.com/pkg/errors"
+
+	"github.com/go-fresh/go-fresh/depmap"
+)
+
+// Dependency tracking modes.
+const (
+	// TrackTags proposes semver releases.
+	TrackTags = "tags"
+	// TrackBranch proposes the head revision of a branch.
+	TrackBranch = "branch"
+)
+
+// defaultBranch returns the default branch of a source, the branch gps orders first. It is
+// tracked when neither the manifest nor the configuration names a branch.
+func defaultBranch(raw []gps.PairedVersion) string {
+	sorted := make([]gps.PairedVersion, len(raw))
+	copy(sorted, raw)
+	gps.SortPairedForUpgrade(sorted)
+
+	for _, v := range sorted {
+		if v.Type() == gps.IsBranch {
+			return v.String()
+		}
+	}
+	return ""
+}
+
+// trackedBranch returns the branch a dependency tracks, if it tracks one. Dependencies without
+// configured tracking track the branch their manifest locks them to, or a branch when their
+// project has no semver tags.
+func trackedBranch(conf *depmap.Config, dep depmap.Dependency, tagged bool, raw []gps.PairedVersion) (string, bool, error) {
+	tracking := conf.TrackingFor(dep.Name)
+	switch {
+	case tracking == TrackTags:
+		return "", false, nil
+	case tracking == TrackBranch, tracking == "" && (dep.Branch != "" || !tagged):
+		if dep.Branch != "" {
+			return dep.Branch, true, nil
+		}
+		return defaultBranch(raw), true, nil
+	case strings.HasPrefix(tracking, TrackBranch+":"):
+		return strings.TrimPrefix(tracking, TrackBranch+":"), true, nil
+	case tracking == "":
+		return "", false, nil
+	}
+	return "", false, errors.Errorf("unknown tracking %q for %s", tracking, dep.Name)
+}
+
+// branchUpdate reports if the branch has moved past the dependency's revision.
+func branchUpdate(id gps.ProjectIdentifier, dep depmap.Dependency, branch string, raw []gps.PairedVersion) (Update, bool) {
+	for _, r := range raw {
+		if r.Type() != gps.IsBranch || r.String() != branch {
+			continue
+		}
+
+		head := r.Revision().String()
+		if dep.Revision == head {
+			// already on the head of the branch
+			return Update{}, false
+		}
+
+		return Update{
+			Name:        dep.Name,
+			ProjectRoot: string(id.ProjectRoot),
+			Revision:    head,
+			Manifest:    dep.Manifest,
+			Manager:     dep.Manager,
+			Source:      id.Source,
+			Branch:      branch,
+
+			From:         dep.Revision,
+			FromRevision: dep.Revision,
+			To:           head,
+		}, true
+	}
+	return Update{}, false
+}
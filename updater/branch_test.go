@@ -0,0 +1,116 @@
+package updater
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/dep/gps"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fresh/go-fresh/depmap"
+)
+
+func TestTrackedBranch(t *testing.T) {
+	raw := []gps.PairedVersion{
+		gps.NewBranch("main").Pair(gps.Revision("rev-main")),
+		gps.NewBranch("release").Pair(gps.Revision("rev-release")),
+	}
+	conf := &depmap.Config{
+		Track: map[string]string{
+			"example.com/tags":    TrackTags,
+			"example.com/branch":  TrackBranch,
+			"example.com/develop": "branch:develop",
+			"example.com/invalid": "commits",
+		},
+	}
+
+	for i, c := range []struct {
+		branch   string
+		tracking bool
+		dep      depmap.Dependency
+		tagged   bool
+	}{
+		{"", false, depmap.Dependency{Name: "example.com/foo"}, true},
+		{"main", true, depmap.Dependency{Name: "example.com/foo"}, false},
+		{"v2", true, depmap.Dependency{Name: "example.com/foo", Branch: "v2"}, false},
+		// locked to a branch, tags of the project are not proposed
+		{"v2", true, depmap.Dependency{Name: "example.com/foo", Branch: "v2"}, true},
+		{"", false, depmap.Dependency{Name: "example.com/tags", Branch: "v2"}, true},
+		{"", false, depmap.Dependency{Name: "example.com/tags"}, false},
+		{"main", true, depmap.Dependency{Name: "example.com/branch/pkg"}, true},
+		{"develop", true, depmap.Dependency{Name: "example.com/develop", Branch: "v2"}, true},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert := require.New(t)
+
+			branch, tracking, err := trackedBranch(conf, c.dep, c.tagged, raw)
+			assert.NoError(err)
+			assert.Equal(c.tracking, tracking)
+			assert.Equal(c.branch, branch)
+		})
+	}
+
+	_, _, err := trackedBranch(conf, depmap.Dependency{Name: "example.com/invalid"}, true, raw)
+	require.Error(t, err)
+}
+
+func TestDefaultBranch(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal("main", defaultBranch([]gps.PairedVersion{
+		gps.NewVersion("latest").Pair(gps.Revision("rev-latest")),
+		gps.NewBranch("main").Pair(gps.Revision("rev-main")),
+	}))
+	assert.Equal("", defaultBranch(pairedVersions("v1.0.0")))
+}
+
+func TestVersionUpdates_Branches(t *testing.T) {
+	raw := []gps.PairedVersion{
+		gps.NewBranch("master").Pair(gps.Revision("rev-master")),
+		gps.NewBranch("release").Pair(gps.Revision("rev-release")),
+	}
+
+	for i, c := range []struct {
+		expected []Update
+		dep      depmap.Dependency
+	}{
+		{
+			[]Update{
+				{Name: "example.com/foo", ProjectRoot: "example.com/foo", Revision: "rev-master", Branch: "master", From: "abcdef", FromRevision: "abcdef", To: "rev-master"},
+			},
+			depmap.Dependency{Name: "example.com/foo", Revision: "abcdef"},
+		},
+		{
+			[]Update{
+				{Name: "example.com/foo", ProjectRoot: "example.com/foo", Revision: "rev-release", Branch: "release", From: "abcdef", FromRevision: "abcdef", To: "rev-release"},
+			},
+			depmap.Dependency{Name: "example.com/foo", Revision: "abcdef", Branch: "release"},
+		},
+		{
+			[]Update{},
+			depmap.Dependency{Name: "example.com/foo", Revision: "rev-master"},
+		},
+		{
+			[]Update{},
+			depmap.Dependency{Name: "example.com/foo", Revision: "abcdef", Branch: "missing"},
+		},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert := require.New(t)
+
+			actual, err := versionUpdates(gps.ProjectIdentifier{ProjectRoot: "example.com/foo"}, []depmap.Dependency{c.dep}, raw, nil, &Options{})
+			assert.NoError(err)
+			assert.Equal(c.expected, actual)
+		})
+	}
+
+	// projects with tags track a branch only when configured to
+	tagged := append(pairedVersions("v1.0.0"), raw...)
+	opts := &Options{Config: &depmap.Config{Track: map[string]string{"example.com/foo": TrackBranch}}}
+
+	actual, err := versionUpdates(gps.ProjectIdentifier{ProjectRoot: "example.com/foo"}, []depmap.Dependency{{Name: "example.com/foo", Revision: "rev-v1.0.0"}}, tagged, nil, opts)
+	require.NoError(t, err)
+	require.Equal(t, []Update{
+		{Name: "example.com/foo", ProjectRoot: "example.com/foo", Revision: "rev-master", Branch: "master", From: "rev-v1.0.0", FromRevision: "rev-v1.0.0", To: "rev-master"},
+	}, actual)
+}
@@ -0,0 +1,128 @@
+package updater
+
+import (
+	"github.com/Masterminds/semver"
+
+	"github.com/go-fresh/go-fresh/depmap"
+)
+
+// Update types, from the least to the most disruptive.
+const (
+	UpdatePatch = "patch"
+	UpdateMinor = "minor"
+	UpdateMajor = "major"
+)
+
+// UpdateType classifies the change between two semver versions as a patch, minor or major update.
+// It returns an empty string if either version can not be parsed.
+func UpdateType(from, to string) string {
+	f, err := semver.NewVersion(from)
+	if err != nil {
+		return ""
+	}
+	t, err := semver.NewVersion(to)
+	if err != nil {
+		return ""
+	}
+
+	switch {
+	case f.Major() != t.Major():
+		return UpdateMajor
+	case f.Minor() != t.Minor():
+		return UpdateMinor
+	default:
+		return UpdatePatch
+	}
+}
+
+// Allowed reports if a project's configuration permits the update. Prerelease updates are
+// only permitted for dependencies that opted in to them, branch updates are only subject
+// to ignores, denied licenses and pins, and security updates and updates off retracted versions
+// only to ignores and denied licenses.
+func Allowed(conf *depmap.Config, u Update) bool {
+	return AllowedByPolicy(conf, PolicyLatest, u)
+}
+
+// AllowedByPolicy is Allowed with the update policy of dependencies the configuration has no
+// policy for, an empty policy is PolicyLatest.
+func AllowedByPolicy(conf *depmap.Config, policy Policy, u Update) bool {
+	if policy == "" {
+		policy = PolicyLatest
+	}
+
+	if conf.Ignored(u.Name) {
+		return false
+	}
+
+	if conf.DeniesLicense(u.ToLicense) {
+		return false
+	}
+
+	if u.Security {
+		// fixing a vulnerability takes priority over the project's update rules
+		return true
+	}
+
+	if u.Retracted {
+		// the current version should not be used at all
+		return true
+	}
+
+	if pin, ok := conf.Pinned(u.Name); ok {
+		c, err := semver.NewConstraint(pin)
+		if err != nil {
+			// an invalid pin should not let updates through
+			return false
+		}
+		v, err := semver.NewVersion(u.To)
+		if err != nil || c.Matches(v) != nil {
+			return false
+		}
+	}
+
+	if u.Branch != "" {
+		// update types and prereleases only apply to tags
+		return true
+	}
+
+	if u.Prerelease || prerelease(u.To) != "" {
+		if !conf.AllowsPrerelease(u.Name, prerelease(u.To)) {
+			return false
+		}
+	}
+
+	updateType := UpdateType(u.From, u.To)
+
+	if name := conf.PolicyFor(u.Name); name != "" {
+		var err error
+		policy, err = ParsePolicy(name)
+		if err != nil {
+			return false
+		}
+	}
+	if !policy.allows(updateType) {
+		return false
+	}
+
+	return conf.Allows(updateType)
+}
+
+// Filter removes the updates a project's configuration does not permit.
+func Filter(conf *depmap.Config, updates []Update) []Update {
+	filtered := make([]Update, 0, len(updates))
+	for _, u := range updates {
+		if Allowed(conf, u) {
+			filtered = append(filtered, u)
+		}
+	}
+	return filtered
+}
+
+// prerelease returns the prerelease information of a semver version, if any.
+func prerelease(version string) string {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return ""
+	}
+	return v.Prerelease()
+}
@@ -0,0 +1,84 @@
+package updater
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fresh/go-fresh/depmap"
+)
+
+func TestUpdateType(t *testing.T) {
+	for i, c := range []struct {
+		expected string
+		from, to string
+	}{
+		{UpdatePatch, "1.2.3", "1.2.4"},
+		{UpdateMinor, "v1.2.3", "v1.3.0"},
+		{UpdateMajor, "1.2.3", "2.0.0"},
+		{"", "", "2.0.0"},
+		{"", "master", "2.0.0"},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			require.Equal(t, c.expected, UpdateType(c.from, c.to))
+		})
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	conf := &depmap.Config{
+		Ignore: []string{"github.com/aws/aws-sdk-go"},
+		Pin:    map[string]string{"github.com/pkg/errors": "~0.8.0"},
+		Allow:  []string{UpdatePatch, UpdateMinor},
+		Policies: map[string]string{
+			"github.com/foo/baz": "patch",
+		},
+		Prereleases: map[string][]string{
+			"github.com/foo/bar": {"rc"},
+			"github.com/foo/qux": {},
+		},
+		DenyLicenses: []string{"copyleft"},
+	}
+
+	for i, c := range []struct {
+		expected bool
+		update   Update
+	}{
+		{true, Update{Name: "github.com/foo/bar", From: "1.0.0", To: "1.1.0"}},
+		{false, Update{Name: "github.com/foo/bar", From: "1.0.0", To: "2.0.0"}},
+		{false, Update{Name: "github.com/foo/bar", To: "1.1.0"}},
+		{false, Update{Name: "github.com/aws/aws-sdk-go/service/s3", From: "1.0.0", To: "1.0.1"}},
+		{true, Update{Name: "github.com/pkg/errors", From: "0.8.0", To: "0.8.1"}},
+		{false, Update{Name: "github.com/pkg/errors", From: "0.8.0", To: "0.9.0"}},
+		{true, Update{Name: "github.com/foo/baz", From: "1.0.0", To: "1.0.1"}},
+		{false, Update{Name: "github.com/foo/baz", From: "1.0.0", To: "1.1.0"}},
+		{true, Update{Name: "github.com/foo/bar", From: "1.0.0", To: "1.1.0-rc.1"}},
+		{false, Update{Name: "github.com/foo/bar", From: "1.0.0", To: "1.1.0-beta.1"}},
+		{false, Update{Name: "github.com/foo/bar", From: "1.0.0", To: "1.1.0", Prerelease: true}},
+		{true, Update{Name: "github.com/foo/qux", From: "1.0.0", To: "1.1.0-alpha"}},
+		{true, Update{Name: "github.com/foo/qux", From: "1.0.0", To: "1.1.0", Prerelease: true}},
+		{false, Update{Name: "github.com/pkg/errors", From: "0.8.0", To: "0.8.1-rc.1"}},
+		{true, Update{Name: "github.com/foo/bar", From: "abcdef", To: "fedcba", Branch: "master"}},
+		{false, Update{Name: "github.com/pkg/errors", From: "abcdef", To: "fedcba", Branch: "master"}},
+		{true, Update{Name: "github.com/pkg/errors", From: "0.8.0", To: "0.9.0", Security: true}},
+		{true, Update{Name: "github.com/foo/baz", From: "1.0.0", To: "2.0.0", Security: true}},
+		{false, Update{Name: "github.com/aws/aws-sdk-go", From: "1.0.0", To: "1.0.1", Security: true}},
+		{true, Update{Name: "github.com/foo/bar", From: "1.0.0", To: "1.1.0", FromLicense: "MIT", ToLicense: "Apache-2.0"}},
+		{false, Update{Name: "github.com/foo/bar", From: "1.0.0", To: "1.1.0", FromLicense: "MIT", ToLicense: "GPL-3.0"}},
+		{false, Update{Name: "github.com/pkg/errors", From: "0.8.0", To: "0.9.0", Security: true, ToLicense: "AGPL-3.0"}},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			require.Equal(t, c.expected, Allowed(conf, c.update))
+		})
+	}
+
+	require.True(t, Allowed(nil, Update{Name: "github.com/foo/bar", To: "2.0.0"}))
+	require.False(t, Allowed(nil, Update{Name: "github.com/foo/bar", To: "2.0.0-rc.1"}))
+
+	// the default policy applies to dependencies without one in the configuration
+	conf = &depmap.Config{Policies: map[string]string{"github.com/foo/baz": "minor"}}
+	require.False(t, AllowedByPolicy(conf, PolicyPatch, Update{Name: "github.com/foo/bar", From: "1.0.0", To: "1.1.0"}))
+	require.True(t, AllowedByPolicy(conf, PolicyPatch, Update{Name: "github.com/foo/bar", From: "1.0.0", To: "1.0.1"}))
+	require.True(t, AllowedByPolicy(conf, PolicyPatch, Update{Name: "github.com/foo/baz", From: "1.0.0", To: "1.1.0"}))
+}
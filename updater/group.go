@@ -0,0 +1,98 @@
+package updater
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/go-fresh/go-fresh/depmap"
+)
+
+// Grouping rules, how updates to a project are combined into PRs.
+const (
+	// GroupNone submits every update on its own.
+	GroupNone = "none"
+	// GroupPrefix combines updates of dependencies sharing a configured prefix, or the same
+	// project root when they match none of the prefixes.
+	GroupPrefix = "prefix"
+	// GroupBump combines updates of the same update type.
+	GroupBump = "bump"
+	// GroupAll combines every update.
+	GroupAll = "all"
+)
+
+// Batch is a group of updates to a project submitted as a single PR.
+type Batch struct {
+	// Name identifies the group, the dependency of single updates.
+	Name string
+
+	Updates []Update
+}
+
+// Group combines updates to a project into batches by the project's grouping rule, GroupNone
+// when it has none. Security updates, updates off retracted versions and import path migrations
+// are always submitted on their own, so they are not held up by other updates. Batches keep the order of their first update.
+func Group(conf *depmap.Config, updates []Update) ([]Batch, error) {
+	rule, prefixes := GroupNone, []string(nil)
+	if conf != nil && conf.Group != "" {
+		rule, prefixes = conf.Group, conf.GroupPrefixes
+	}
+
+	var key func(Update) string
+	switch rule {
+	case GroupNone:
+		key = func(Update) string { return "" }
+	case GroupPrefix:
+		key = func(u Update) string {
+			for _, prefix := range prefixes {
+				// prefixes match whole path elements, with or without a trailing slash
+				p := strings.TrimSuffix(prefix, "/")
+				if u.Name == p || strings.HasPrefix(u.Name, p+"/") {
+					return prefix
+				}
+			}
+			if u.ProjectRoot != "" {
+				return u.ProjectRoot
+			}
+			return u.Name
+		}
+	case GroupBump:
+		key = func(u Update) string {
+			if bump := updateBump(u); bump != "" {
+				return bump
+			}
+			return "other"
+		}
+	case GroupAll:
+		key = func(Update) string { return GroupAll }
+	default:
+		return nil, errors.Errorf("unknown grouping rule %q", rule)
+	}
+
+	batches := []Batch{}
+	index := map[string]int{}
+	for _, u := range updates {
+		k := key(u)
+		if k == "" || u.Security || u.Retracted || u.Migration {
+			batches = append(batches, Batch{Name: u.Name, Updates: []Update{u}})
+			continue
+		}
+
+		i, ok := index[k]
+		if !ok {
+			i = len(batches)
+			index[k] = i
+			batches = append(batches, Batch{Name: k})
+		}
+		batches[i].Updates = append(batches[i].Updates, u)
+	}
+	return batches, nil
+}
+
+// updateBump returns the update type of u, computed from its versions when not set.
+func updateBump(u Update) string {
+	if u.Bump != "" {
+		return u.Bump
+	}
+	return UpdateType(u.From, u.To)
+}
@@ -0,0 +1,78 @@
+package updater
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fresh/go-fresh/depmap"
+)
+
+func TestGroup(t *testing.T) {
+	updates := []Update{
+		{Name: "github.com/aws/aws-sdk-go/aws", ProjectRoot: "github.com/aws/aws-sdk-go", From: "1.0.0", To: "1.1.0"},
+		{Name: "github.com/pkg/errors", ProjectRoot: "github.com/pkg/errors", From: "0.8.0", To: "0.8.1"},
+		{Name: "github.com/aws/aws-xray-sdk-go", ProjectRoot: "github.com/aws/aws-xray-sdk-go", From: "1.0.0", To: "1.0.1"},
+		{Name: "github.com/foo/bar", ProjectRoot: "github.com/foo/bar", From: "1.0.0", To: "1.0.1", Security: true},
+		{Name: "github.com/aws/aws-sdk-go/service/s3", ProjectRoot: "github.com/aws/aws-sdk-go", From: "1.0.0", To: "1.1.0"},
+	}
+
+	for i, c := range []struct {
+		conf     *depmap.Config
+		expected map[string][]int
+	}{
+		{nil, map[string][]int{
+			"github.com/aws/aws-sdk-go/aws":        {0},
+			"github.com/pkg/errors":                {1},
+			"github.com/aws/aws-xray-sdk-go":       {2},
+			"github.com/foo/bar":                   {3},
+			"github.com/aws/aws-sdk-go/service/s3": {4},
+		}},
+		{&depmap.Config{Group: GroupPrefix}, map[string][]int{
+			"github.com/aws/aws-sdk-go":      {0, 4},
+			"github.com/pkg/errors":          {1},
+			"github.com/aws/aws-xray-sdk-go": {2},
+			"github.com/foo/bar":             {3},
+		}},
+		{&depmap.Config{Group: GroupPrefix, GroupPrefixes: []string{"github.com/aws/"}}, map[string][]int{
+			"github.com/aws/":       {0, 2, 4},
+			"github.com/pkg/errors": {1},
+			"github.com/foo/bar":    {3},
+		}},
+		{&depmap.Config{Group: GroupPrefix, GroupPrefixes: []string{"github.com/aws/aws", "github.com/aws/aws-sdk-go"}}, map[string][]int{
+			"github.com/aws/aws-sdk-go":      {0, 4},
+			"github.com/pkg/errors":          {1},
+			"github.com/aws/aws-xray-sdk-go": {2},
+			"github.com/foo/bar":             {3},
+		}},
+		{&depmap.Config{Group: GroupBump}, map[string][]int{
+			UpdateMinor:          {0, 4},
+			UpdatePatch:          {1, 2},
+			"github.com/foo/bar": {3},
+		}},
+		{&depmap.Config{Group: GroupAll}, map[string][]int{
+			GroupAll:             {0, 1, 2, 4},
+			"github.com/foo/bar": {3},
+		}},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert := require.New(t)
+
+			batches, err := Group(c.conf, updates)
+			assert.NoError(err)
+			assert.Len(batches, len(c.expected))
+
+			for _, b := range batches {
+				expected := []Update{}
+				for _, j := range c.expected[b.Name] {
+					expected = append(expected, updates[j])
+				}
+				assert.Equal(expected, b.Updates, b.Name)
+			}
+		})
+	}
+
+	_, err := Group(&depmap.Config{Group: "dependency"}, updates)
+	require.Error(t, err)
+}
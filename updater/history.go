@@ -0,0 +1,433 @@
+package updater
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/storer"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
+
+	"github.com/go-fresh/go-fresh/apidiff"
+	"github.com/go-fresh/go-fresh/depmap"
+	"github.com/go-fresh/go-fresh/license"
+	"github.com/go-fresh/go-fresh/vuln"
+)
+
+// History inspects the commit history of dependency source repositories.
+type History interface {
+	// Compare reports how far the from revision is behind the to revision in the repository
+	// at gitURL. Revisions are commit hashes or tags, branch is the branch to read history from,
+	// the repository's default branch when empty.
+	Compare(ctx context.Context, gitURL, branch, from, to string) (Staleness, error)
+}
+
+// LicenseDetector detects the licenses of dependency source repositories.
+type LicenseDetector interface {
+	// Licenses returns the SPDX identifier of the license at each of the revisions of the
+	// repository at gitURL, license.None for revisions without a license file.
+	Licenses(ctx context.Context, gitURL, branch string, revisions ...string) ([]string, error)
+}
+
+// APIAnalyzer compares the exported API of dependency packages between revisions.
+type APIAnalyzer interface {
+	// CompareAPI returns the breaking changes from the from revision to the to revision of the
+	// repository at gitURL to the packages, import paths mapped to their directory in the repository.
+	CompareAPI(ctx context.Context, gitURL, branch, from, to string, pkgs map[string]string) ([]apidiff.Change, error)
+}
+
+// Staleness describes how far one revision is behind another.
+type Staleness struct {
+	// Commits is the number of commits reachable from the newer revision but not the older one.
+	Commits int
+
+	// FromDate and ToDate are the commit dates of the revisions.
+	FromDate time.Time
+	ToDate   time.Time
+}
+
+// SortByStaleness orders updates from the most to the least stale dependency, by time
+// behind and then by commits behind.
+func SortByStaleness(updates []Update) {
+	sort.SliceStable(updates, func(i, j int) bool {
+		if updates[i].TimeBehind != updates[j].TimeBehind {
+			return updates[i].TimeBehind > updates[j].TimeBehind
+		}
+		return updates[i].CommitsBehind > updates[j].CommitsBehind
+	})
+}
+
+// Stale reports if the update's dependency is more than maxCommits commits or maxAge behind.
+// A zero limit is not checked.
+func Stale(u Update, maxCommits int, maxAge time.Duration) bool {
+	return (maxCommits > 0 && u.CommitsBehind > maxCommits) || (maxAge > 0 && u.TimeBehind > maxAge)
+}
+
+// GitHistory reads history from clones of the source repositories. Clones are kept in Cache
+// when it is set, otherwise repositories are cloned in memory. Either way a source held with
+// Hold is opened once and shared by all calls until it is released.
+type GitHistory struct {
+	Cache *depmap.Cache
+
+	// Credentials authenticates to private repositories, it may be nil.
+	Credentials *depmap.Credentials
+
+	mu      sync.Mutex
+	sources map[string]*heldSource
+}
+
+// heldSource is a source kept open by Hold, with a repository per branch opened so far.
+type heldSource struct {
+	holds int
+
+	mu       sync.Mutex
+	repos    map[string]*git.Repository
+	releases []func()
+}
+
+// NewGitHistory creates a History backed by git clones, cache and creds may be nil.
+func NewGitHistory(cache *depmap.Cache, creds *depmap.Credentials) *GitHistory {
+	return &GitHistory{Cache: cache, Credentials: creds}
+}
+
+// Hold shares the repositories opened for gitURL between calls until release is called, so
+// inspecting several updates of a project clones or fetches its source once.
+func (h *GitHistory) Hold(gitURL string) (release func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.sources == nil {
+		h.sources = map[string]*heldSource{}
+	}
+	s, ok := h.sources[gitURL]
+	if !ok {
+		s = &heldSource{repos: map[string]*git.Repository{}}
+		h.sources[gitURL] = s
+	}
+	s.holds++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			h.mu.Lock()
+			s.holds--
+			last := s.holds == 0
+			if last {
+				delete(h.sources, gitURL)
+			}
+			h.mu.Unlock()
+
+			if last {
+				s.mu.Lock()
+				defer s.mu.Unlock()
+				for _, release := range s.releases {
+					release()
+				}
+			}
+		})
+	}
+}
+
+// open returns the repository at gitURL, and a function to call once it is no longer used.
+func (h *GitHistory) open(ctx context.Context, gitURL, branch string) (*git.Repository, func(), error) {
+	h.mu.Lock()
+	s := h.sources[gitURL]
+	h.mu.Unlock()
+	if s == nil {
+		return h.openSource(ctx, gitURL, branch)
+	}
+
+	// callers of a held source wait for the first one to open it
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if repo, ok := s.repos[branch]; ok {
+		return repo, func() {}, nil
+	}
+	repo, release, err := h.openSource(ctx, gitURL, branch)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.repos[branch] = repo
+	s.releases = append(s.releases, release)
+	return repo, func() {}, nil
+}
+
+func (h *GitHistory) openSource(ctx context.Context, gitURL, branch string) (*git.Repository, func(), error) {
+	auth, err := h.Credentials.AuthMethod(gitURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if h.Cache != nil {
+		repo, err := h.Cache.Fetch(ctx, gitURL, branch, auth)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repo, func() { h.Cache.Release(gitURL) }, nil
+	}
+
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), nil, &git.CloneOptions{
+		URL:           gitURL,
+		Auth:          auth,
+		ReferenceName: depmap.BranchReference(branch),
+		Tags:          git.AllTags,
+	})
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "unable to clone repository %s", gitURL)
+	}
+	return repo, func() {}, nil
+}
+
+// Compare implements History.
+func (h *GitHistory) Compare(ctx context.Context, gitURL, branch, from, to string) (Staleness, error) {
+	repo, release, err := h.open(ctx, gitURL, branch)
+	if err != nil {
+		return Staleness{}, err
+	}
+	defer release()
+	return compareRevisions(repo, from, to)
+}
+
+// Licenses implements LicenseDetector.
+func (h *GitHistory) Licenses(ctx context.Context, gitURL, branch string, revisions ...string) ([]string, error) {
+	repo, release, err := h.open(ctx, gitURL, branch)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	licenses := make([]string, 0, len(revisions))
+	for _, revision := range revisions {
+		id, err := revisionLicense(repo, revision)
+		if err != nil {
+			return nil, err
+		}
+		licenses = append(licenses, id)
+	}
+	return licenses, nil
+}
+
+// revisionLicense classifies the license of the repository at the revision.
+func revisionLicense(repo *git.Repository, revision string) (string, error) {
+	tree, err := revisionTree(repo, revision)
+	if err != nil {
+		return "", err
+	}
+	id, err := license.FromTree(tree)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to detect license at %s", revision)
+	}
+	return id, nil
+}
+
+// Ancestry adapts the history to match advisories to revisions. Repositories are opened with ctx,
+// each check is limited to timeout unless it is zero. Without a cache, the last clone is reused.
+func (h *GitHistory) Ancestry(ctx context.Context, timeout time.Duration) vuln.Ancestry {
+	return &gitAncestry{ctx: ctx, timeout: timeout, history: h}
+}
+
+type gitAncestry struct {
+	ctx     context.Context
+	timeout time.Duration
+	history *GitHistory
+
+	mu      sync.Mutex
+	lastURL string
+	last    *git.Repository
+}
+
+// Hold implements the sharing of GitHistory.Hold.
+func (a *gitAncestry) Hold(gitURL string) func() {
+	return a.history.Hold(gitURL)
+}
+
+func (a *gitAncestry) IsAncestor(gitURL, ancestor, descendant string) (bool, error) {
+	var found bool
+	err := withTimeout(a.ctx, a.timeout, func(ctx context.Context) error {
+		repo, release, err := a.open(ctx, gitURL)
+		if err != nil {
+			return err
+		}
+		defer release()
+		found, err = isAncestor(repo, ancestor, descendant)
+		return err
+	})
+	return found, err
+}
+
+// open returns the repository at gitURL, reusing the last in-memory clone when it is the same.
+func (a *gitAncestry) open(ctx context.Context, gitURL string) (*git.Repository, func(), error) {
+	if a.history.Cache != nil {
+		return a.history.open(ctx, gitURL, "")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.last == nil || a.lastURL != gitURL {
+		repo, _, err := a.history.open(ctx, gitURL, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		a.lastURL, a.last = gitURL, repo
+	}
+	return a.last, func() {}, nil
+}
+
+func isAncestor(repo *git.Repository, ancestor, descendant string) (bool, error) {
+	ancestorCommit, err := resolveCommit(repo, ancestor)
+	if err != nil {
+		return false, err
+	}
+	descendantCommit, err := resolveCommit(repo, descendant)
+	if err != nil {
+		return false, err
+	}
+
+	found := false
+	err = walkCommits(repo, descendantCommit, func(c *object.Commit) error {
+		if c.Hash == ancestorCommit.Hash {
+			found = true
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return found, nil
+}
+
+// CompareAPI implements APIAnalyzer.
+func (h *GitHistory) CompareAPI(ctx context.Context, gitURL, branch, from, to string, pkgs map[string]string) ([]apidiff.Change, error) {
+	repo, release, err := h.open(ctx, gitURL, branch)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return compareAPIRevisions(repo, from, to, pkgs)
+}
+
+// compareAPIRevisions compares the API of the packages between the revisions. Packages missing
+// from the new revision are reported as removed, those missing from the old one are skipped.
+func compareAPIRevisions(repo *git.Repository, from, to string, pkgs map[string]string) ([]apidiff.Change, error) {
+	fromTree, err := revisionTree(repo, from)
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := revisionTree(repo, to)
+	if err != nil {
+		return nil, err
+	}
+
+	imps := make([]string, 0, len(pkgs))
+	for imp := range pkgs {
+		imps = append(imps, imp)
+	}
+	sort.Strings(imps)
+
+	changes := []apidiff.Change{}
+	for _, imp := range imps {
+		old, err := apidiff.FromTree(fromTree, pkgs[imp], imp)
+		if err == apidiff.ErrNoPackage {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to load %s at %s", imp, from)
+		}
+
+		new, err := apidiff.FromTree(toTree, pkgs[imp], imp)
+		if err == apidiff.ErrNoPackage {
+			changes = append(changes, apidiff.Change{Package: imp, Kind: apidiff.Removed})
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to load %s at %s", imp, to)
+		}
+
+		changes = append(changes, apidiff.Compare(old, new)...)
+	}
+	return changes, nil
+}
+
+func revisionTree(repo *git.Repository, revision string) (*object.Tree, error) {
+	c, err := resolveCommit(repo, revision)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read tree of %s", revision)
+	}
+	return tree, nil
+}
+
+// compareRevisions measures how far the from revision is behind the to revision.
+func compareRevisions(repo *git.Repository, from, to string) (Staleness, error) {
+	fromCommit, err := resolveCommit(repo, from)
+	if err != nil {
+		return Staleness{}, err
+	}
+	toCommit, err := resolveCommit(repo, to)
+	if err != nil {
+		return Staleness{}, err
+	}
+
+	seen := map[plumbing.Hash]bool{}
+	err = walkCommits(repo, fromCommit, func(c *object.Commit) error {
+		seen[c.Hash] = true
+		return nil
+	})
+	if err != nil {
+		return Staleness{}, err
+	}
+
+	s := Staleness{
+		FromDate: fromCommit.Committer.When,
+		ToDate:   toCommit.Committer.When,
+	}
+	err = walkCommits(repo, toCommit, func(c *object.Commit) error {
+		if !seen[c.Hash] {
+			s.Commits++
+		}
+		return nil
+	})
+	if err != nil {
+		return Staleness{}, err
+	}
+	return s, nil
+}
+
+func resolveCommit(repo *git.Repository, revision string) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to resolve revision %s", revision)
+	}
+
+	c, err := repo.CommitObject(*hash)
+	if err == plumbing.ErrObjectNotFound {
+		// annotated tags point to a tag object
+		tag, tagErr := repo.TagObject(*hash)
+		if tagErr == nil {
+			c, err = tag.Commit()
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to find commit %s", revision)
+	}
+	return c, nil
+}
+
+func walkCommits(repo *git.Repository, from *object.Commit, fn func(*object.Commit) error) error {
+	iter, err := repo.Log(&git.LogOptions{From: from.Hash})
+	if err != nil {
+		return errors.Wrapf(err, "unable to read history of %s", from.Hash)
+	}
+	defer iter.Close()
+
+	return iter.ForEach(fn)
+}
@@ -0,0 +1,180 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+
+	"github.com/go-fresh/go-fresh/license"
+)
+
+func TestCompareRevisions(t *testing.T) {
+	assert := require.New(t)
+
+	tmp, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+	defer os.RemoveAll(tmp)
+
+	repo, err := git.PlainInit(tmp, false)
+	assert.NoError(err)
+	tree, err := repo.Worktree()
+	assert.NoError(err)
+
+	start := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	revisions := []string{}
+	for i := 0; i < 4; i++ {
+		name := fmt.Sprintf("file%d", i)
+		assert.NoError(ioutil.WriteFile(filepath.Join(tmp, name), []byte(name), 0644))
+		_, err = tree.Add(name)
+		assert.NoError(err)
+
+		sig := &object.Signature{Name: "test", Email: "test@example.com", When: start.AddDate(0, 0, i)}
+		hash, err := tree.Commit(name, &git.CommitOptions{Author: sig, Committer: sig})
+		assert.NoError(err)
+		revisions = append(revisions, hash.String())
+	}
+
+	err = repo.Storer.SetReference(plumbing.NewHashReference("refs/tags/v1.0.0", plumbing.NewHash(revisions[1])))
+	assert.NoError(err)
+
+	s, err := compareRevisions(repo, revisions[0], revisions[3])
+	assert.NoError(err)
+	assert.Equal(Staleness{Commits: 3, FromDate: start, ToDate: start.AddDate(0, 0, 3)}, utcStaleness(s))
+
+	s, err = compareRevisions(repo, "v1.0.0", revisions[3])
+	assert.NoError(err)
+	assert.Equal(Staleness{Commits: 2, FromDate: start.AddDate(0, 0, 1), ToDate: start.AddDate(0, 0, 3)}, utcStaleness(s))
+
+	s, err = compareRevisions(repo, revisions[3], revisions[3])
+	assert.NoError(err)
+	assert.Equal(0, s.Commits)
+
+	_, err = compareRevisions(repo, "0123456789012345678901234567890123456789", revisions[3])
+	assert.Error(err)
+	for i, c := range []struct {
+		expected             bool
+		ancestor, descendant string
+	}{
+		{true, revisions[0], revisions[3]},
+		{true, "v1.0.0", revisions[2]},
+		{true, revisions[2], revisions[2]},
+		{false, revisions[3], revisions[1]},
+	} {
+		ok, err := isAncestor(repo, c.ancestor, c.descendant)
+		assert.NoError(err, "%d", i)
+		assert.Equal(c.expected, ok, "%d", i)
+	}
+}
+
+func TestGitHistoryHold(t *testing.T) {
+	assert := require.New(t)
+
+	tmp, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+	defer os.RemoveAll(tmp)
+
+	repo, err := git.PlainInit(tmp, false)
+	assert.NoError(err)
+	tree, err := repo.Worktree()
+	assert.NoError(err)
+	assert.NoError(ioutil.WriteFile(filepath.Join(tmp, "README"), []byte("readme"), 0644))
+	_, err = tree.Add("README")
+	assert.NoError(err)
+	sig := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	_, err = tree.Commit("readme", &git.CommitOptions{Author: sig, Committer: sig})
+	assert.NoError(err)
+
+	ctx := context.Background()
+	h := NewGitHistory(nil, nil)
+
+	// a held source is cloned once
+	release := h.Hold(tmp)
+	first, _, err := h.open(ctx, tmp, "")
+	assert.NoError(err)
+	second, _, err := h.open(ctx, tmp, "")
+	assert.NoError(err)
+	assert.True(first == second)
+
+	release()
+	third, _, err := h.open(ctx, tmp, "")
+	assert.NoError(err)
+	assert.False(first == third)
+	assert.Empty(h.sources)
+}
+
+func utcStaleness(s Staleness) Staleness {
+	s.FromDate = s.FromDate.UTC()
+	s.ToDate = s.ToDate.UTC()
+	return s
+}
+
+func TestSortByStaleness(t *testing.T) {
+	updates := []Update{
+		{Name: "a", CommitsBehind: 10, TimeBehind: time.Hour},
+		{Name: "b", CommitsBehind: 1, TimeBehind: 48 * time.Hour},
+		{Name: "c"},
+		{Name: "d", CommitsBehind: 20, TimeBehind: time.Hour},
+	}
+	SortByStaleness(updates)
+
+	names := []string{}
+	for _, u := range updates {
+		names = append(names, u.Name)
+	}
+	require.Equal(t, []string{"b", "d", "a", "c"}, names)
+
+	require.True(t, Stale(updates[0], 0, 24*time.Hour))
+	require.False(t, Stale(updates[0], 5, 0))
+	require.True(t, Stale(updates[1], 5, 0))
+	require.False(t, Stale(updates[3], 0, 0))
+}
+
+func TestRevisionLicense(t *testing.T) {
+	assert := require.New(t)
+
+	tmp, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+	defer os.RemoveAll(tmp)
+
+	repo, err := git.PlainInit(tmp, false)
+	assert.NoError(err)
+	tree, err := repo.Worktree()
+	assert.NoError(err)
+
+	sig := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	revisions := []string{}
+	for _, text := range []string{
+		"",
+		"Permission is hereby granted, free of charge, to any person obtaining a copy",
+		"GNU GENERAL PUBLIC LICENSE\n  Version 3, 29 June 2007",
+	} {
+		name := "README"
+		content := "readme"
+		if text != "" {
+			name = "LICENSE"
+			content = text
+		}
+		assert.NoError(ioutil.WriteFile(filepath.Join(tmp, name), []byte(content), 0644))
+		_, err = tree.Add(name)
+		assert.NoError(err)
+
+		hash, err := tree.Commit(name, &git.CommitOptions{Author: sig, Committer: sig})
+		assert.NoError(err)
+		revisions = append(revisions, hash.String())
+	}
+
+	for i, expected := range []string{license.None, license.MIT, license.GPL30} {
+		id, err := revisionLicense(repo, revisions[i])
+		assert.NoError(err)
+		assert.Equal(expected, id)
+	}
+}
@@ -0,0 +1,146 @@
+package updater
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"golang.org/x/mod/module"
+
+	"github.com/go-fresh/go-fresh/depmap"
+)
+
+// modulePath is the semantic import versioning of a Go module dependency. Major versions from
+// v2 on are imported with a "/vN" suffix, or ".vN" for gopkg.in, unless the module predates
+// modules and is required as "+incompatible".
+type modulePath struct {
+	prefix       string
+	pathMajor    string
+	incompatible bool
+}
+
+// parseModulePath returns the module path of a Go module dependency.
+func parseModulePath(dep depmap.Dependency) (modulePath, bool) {
+	if !dep.Module {
+		return modulePath{}, false
+	}
+
+	prefix, pathMajor, ok := module.SplitPathVersion(dep.Name)
+	if !ok {
+		return modulePath{}, false
+	}
+
+	return modulePath{
+		prefix:       prefix,
+		pathMajor:    pathMajor,
+		incompatible: strings.HasSuffix(dep.Version, "+incompatible"),
+	}, true
+}
+
+// major returns the major version of the path, 1 for paths without a suffix.
+func (m modulePath) major() uint64 {
+	n, err := strconv.ParseUint(strings.TrimLeft(m.pathMajor, "/.v"), 10, 64)
+	if err != nil {
+		return 1
+	}
+	return n
+}
+
+// inPath reports if the version can be required with the module's current path.
+func (m modulePath) inPath(v semver.Version) bool {
+	if m.pathMajor == "" {
+		return v.Major() <= 1 || m.incompatible
+	}
+	return v.Major() == m.major()
+}
+
+// path returns the module path of the major version.
+func (m modulePath) path(major uint64) string {
+	if strings.HasPrefix(m.prefix, "gopkg.in/") {
+		return fmt.Sprintf("%s.v%d", m.prefix, major)
+	}
+	if major <= 1 {
+		return m.prefix
+	}
+	return fmt.Sprintf("%s/v%d", m.prefix, major)
+}
+
+// version returns the version as it is required with the module's current path.
+func (m modulePath) version(v semver.Version) string {
+	if m.incompatible && v.Major() >= 2 && v.Metadata() == "" {
+		return v.String() + "+incompatible"
+	}
+	return v.String()
+}
+
+// split separates the sorted versions that keep the module's path from the newest version
+// of a higher major version, which requires migrating to a new path.
+func (m modulePath) split(sorted []semver.Version) ([]semver.Version, *semver.Version) {
+	inPath := make([]semver.Version, 0, len(sorted))
+	var migration *semver.Version
+	for i := range sorted {
+		v := sorted[i]
+		switch {
+		case m.inPath(v):
+			inPath = append(inPath, v)
+		case v.Major() > m.major():
+			migration = &v
+		}
+	}
+	return inPath, migration
+}
+
+// RequiresNewerMajor reports if the manifest of a Go module dependency also requires a higher
+// major version of the module. Migrating the dependency is then left to the updates of that
+// version, which are updated in their own path.
+func RequiresNewerMajor(deps []depmap.Dependency, dep depmap.Dependency) bool {
+	mod, ok := parseModulePath(dep)
+	if !ok {
+		return false
+	}
+	for _, d := range deps {
+		other, ok := parseModulePath(d)
+		if !ok || other.prefix != mod.prefix || manifestDir(d.Manifest) != manifestDir(dep.Manifest) {
+			continue
+		}
+		if other.major() > mod.major() {
+			return true
+		}
+	}
+	return false
+}
+
+// manifestDir returns the manifest directory, the project root when unset.
+func manifestDir(manifest string) string {
+	if manifest == "" {
+		return "."
+	}
+	return manifest
+}
+
+// ModuleUpdate adjusts an update of a dependency to the semantic import versioning of Go
+// modules. Versions beyond the module's major version become path migrations, and versions
+// of lower major versions, which are other modules, are reported as not applicable.
+func ModuleUpdate(dep depmap.Dependency, u Update) (Update, bool) {
+	mod, ok := parseModulePath(dep)
+	if !ok {
+		return u, true
+	}
+	to, err := semver.NewVersion(u.To)
+	if err != nil {
+		return u, true
+	}
+
+	switch {
+	case mod.inPath(to):
+		u.To = mod.version(to)
+		return u, true
+	case to.Major() > mod.major():
+		u.Migration = true
+		u.Path = mod.path(to.Major())
+		u.Bump = UpdateMajor
+		return u, true
+	}
+	return u, false
+}
@@ -0,0 +1,123 @@
+package updater
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Masterminds/semver"
+	"github.com/golang/dep/gps"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fresh/go-fresh/depmap"
+)
+
+func TestParseModulePath(t *testing.T) {
+	for i, c := range []struct {
+		dep      depmap.Dependency
+		ok       bool
+		major    uint64
+		newPath  string
+		inPath   string
+		notPath  string
+		required string
+	}{
+		{depmap.Dependency{Name: "github.com/foo/bar", Module: true}, true, 1, "github.com/foo/bar/v3", "1.9.0", "2.0.0", "1.9.0"},
+		{depmap.Dependency{Name: "github.com/foo/bar/v2", Module: true}, true, 2, "github.com/foo/bar/v3", "2.1.0", "1.9.0", "2.1.0"},
+		{depmap.Dependency{Name: "github.com/foo/bar", Version: "v2.0.0+incompatible", Module: true}, true, 1, "github.com/foo/bar/v3", "3.0.0", "", "3.0.0+incompatible"},
+		{depmap.Dependency{Name: "gopkg.in/yaml.v2", Module: true}, true, 2, "gopkg.in/yaml.v3", "2.2.1", "3.0.0", "2.2.1"},
+		{depmap.Dependency{Name: "github.com/foo/bar/v2"}, false, 0, "", "", "", ""},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert := require.New(t)
+
+			mod, ok := parseModulePath(c.dep)
+			assert.Equal(c.ok, ok)
+			if !ok {
+				return
+			}
+
+			assert.Equal(c.major, mod.major())
+			assert.Equal(c.newPath, mod.path(3))
+
+			v := mustVersion(t, c.inPath)
+			assert.True(mod.inPath(v))
+			assert.Equal(c.required, mod.version(v))
+			if c.notPath != "" {
+				assert.False(mod.inPath(mustVersion(t, c.notPath)))
+			}
+		})
+	}
+}
+
+func TestVersionUpdates_Modules(t *testing.T) {
+	raw := pairedVersions("v1.8.0", "v1.9.0", "v2.0.0", "v2.1.0", "v3.0.0")
+
+	for i, c := range []struct {
+		expected []Update
+		dep      depmap.Dependency
+	}{
+		{
+			[]Update{
+				{Name: "github.com/foo/bar", ProjectRoot: "github.com/foo/bar", Revision: "rev-v1.9.0", From: "1.8.0", FromRevision: "rev-v1.8.0", To: "1.9.0", Bump: UpdateMinor},
+				{Name: "github.com/foo/bar", ProjectRoot: "github.com/foo/bar", Revision: "rev-v3.0.0", From: "1.8.0", FromRevision: "rev-v1.8.0", To: "3.0.0", Bump: UpdateMajor, Migration: true, Path: "github.com/foo/bar/v3"},
+			},
+			depmap.Dependency{Name: "github.com/foo/bar", Revision: "v1.8.0", Version: "v1.8.0", Module: true},
+		},
+		{
+			[]Update{
+				{Name: "github.com/foo/bar/v2", ProjectRoot: "github.com/foo/bar", Revision: "rev-v2.1.0", From: "2.0.0", FromRevision: "rev-v2.0.0", To: "2.1.0", Bump: UpdateMinor},
+				{Name: "github.com/foo/bar/v2", ProjectRoot: "github.com/foo/bar", Revision: "rev-v3.0.0", From: "2.0.0", FromRevision: "rev-v2.0.0", To: "3.0.0", Bump: UpdateMajor, Migration: true, Path: "github.com/foo/bar/v3"},
+			},
+			depmap.Dependency{Name: "github.com/foo/bar/v2", Revision: "v2.0.0", Version: "v2.0.0", Module: true},
+		},
+		{
+			[]Update{
+				{Name: "github.com/foo/bar", ProjectRoot: "github.com/foo/bar", Revision: "rev-v2.1.0", From: "2.0.0", FromRevision: "rev-v2.0.0", To: "2.1.0+incompatible", Bump: UpdateMinor},
+				{Name: "github.com/foo/bar", ProjectRoot: "github.com/foo/bar", Revision: "rev-v3.0.0", From: "2.0.0", FromRevision: "rev-v2.0.0", To: "3.0.0+incompatible", Bump: UpdateMajor},
+			},
+			depmap.Dependency{Name: "github.com/foo/bar", Revision: "v2.0.0", Version: "v2.0.0+incompatible", Module: true},
+		},
+		{
+			[]Update{
+				{Name: "github.com/foo/bar", ProjectRoot: "github.com/foo/bar", Revision: "rev-v1.9.0", From: "1.8.0", FromRevision: "rev-v1.8.0", To: "1.9.0", Bump: UpdateMinor},
+				{Name: "github.com/foo/bar", ProjectRoot: "github.com/foo/bar", Revision: "rev-v3.0.0", From: "1.8.0", FromRevision: "rev-v1.8.0", To: "3.0.0", Bump: UpdateMajor},
+			},
+			depmap.Dependency{Name: "github.com/foo/bar", Revision: "v1.8.0", Version: "v1.8.0"},
+		},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert := require.New(t)
+
+			actual, err := versionUpdates(gps.ProjectIdentifier{ProjectRoot: "github.com/foo/bar"}, []depmap.Dependency{c.dep}, raw, nil, &Options{})
+			assert.NoError(err)
+			assert.Equal(c.expected, actual)
+		})
+	}
+}
+
+func TestVersionUpdates_ModuleMajors(t *testing.T) {
+	assert := require.New(t)
+
+	raw := pairedVersions("v1.8.0", "v1.9.0", "v2.0.0", "v2.1.0")
+	deps := []depmap.Dependency{
+		{Name: "github.com/foo/bar", Manifest: ".", Revision: "v1.8.0", Version: "v1.8.0", Module: true},
+		{Name: "github.com/foo/bar/v2", Manifest: ".", Revision: "v2.0.0", Version: "v2.0.0", Module: true},
+		{Name: "github.com/foo/bar", Manifest: "tools", Revision: "v1.8.0", Version: "v1.8.0", Module: true},
+	}
+
+	// the root manifest already requires v2, only the tools manifest migrates
+	actual, err := versionUpdates(gps.ProjectIdentifier{ProjectRoot: "github.com/foo/bar"}, deps, raw, nil, &Options{})
+	assert.NoError(err)
+	assert.Equal([]Update{
+		{Name: "github.com/foo/bar", ProjectRoot: "github.com/foo/bar", Revision: "rev-v1.9.0", Manifest: ".", From: "1.8.0", FromRevision: "rev-v1.8.0", To: "1.9.0", Bump: UpdateMinor},
+		{Name: "github.com/foo/bar/v2", ProjectRoot: "github.com/foo/bar", Revision: "rev-v2.1.0", Manifest: ".", From: "2.0.0", FromRevision: "rev-v2.0.0", To: "2.1.0", Bump: UpdateMinor},
+		{Name: "github.com/foo/bar", ProjectRoot: "github.com/foo/bar", Revision: "rev-v1.9.0", Manifest: "tools", From: "1.8.0", FromRevision: "rev-v1.8.0", To: "1.9.0", Bump: UpdateMinor},
+		{Name: "github.com/foo/bar", ProjectRoot: "github.com/foo/bar", Revision: "rev-v2.1.0", Manifest: "tools", From: "1.8.0", FromRevision: "rev-v1.8.0", To: "2.1.0", Bump: UpdateMajor, Migration: true, Path: "github.com/foo/bar/v2"},
+	}, actual)
+}
+
+func mustVersion(t *testing.T, s string) semver.Version {
+	v, err := semver.NewVersion(s)
+	require.NoError(t, err)
+	return v
+}
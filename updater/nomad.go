@@ -2,7 +2,9 @@ package updater
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/nomad/api"
@@ -12,9 +14,23 @@ import (
 )
 
 const (
-	nomadJobIDGovendor = "go-fresh-pr-govendor"
+	// nomadJobIDPrefix is followed by the dependency manager name to form the ID of the
+	// parameterized job applying its updates, for example "go-fresh-pr-govendor".
+	nomadJobIDPrefix = "go-fresh-pr-"
+
+	// nomadDefaultManager applies updates without a recorded manager, from projects
+	// registered when govendor was the only one supported.
+	nomadDefaultManager = "govendor"
 )
 
+// nomadManager returns the dependency manager of the update's manifest.
+func nomadManager(u Update) string {
+	if u.Manager == "" {
+		return nomadDefaultManager
+	}
+	return u.Manager
+}
+
 type nomadSubmitter struct {
 	client  *api.Client
 	timeout time.Duration
@@ -85,17 +101,179 @@ func (s *nomadSubmitter) JobComplete(ctx context.Context, id string) (bool, erro
 	}
 }
 
-func (s *nomadSubmitter) SubmitPR(ctx context.Context, project depmap.Project, dependency, toversion string) error {
-	// QUESTION: does the nomad API not use context.Context?
-	resp, _, err := s.client.Jobs().Dispatch(nomadJobIDGovendor, map[string]string{
+func (s *nomadSubmitter) SubmitPR(ctx context.Context, project depmap.Project, u Update) error {
+	return s.dispatch(ctx, nomadJobIDPrefix+nomadManager(u), updateMeta(project, u))
+}
+
+// SubmitBatch dispatches a job per dependency manager of the batch's updates, each job only
+// knows how to rewrite the manifests of its own manager.
+func (s *nomadSubmitter) SubmitBatch(ctx context.Context, project depmap.Project, batch Batch) error {
+	for _, b := range splitByManager(batch) {
+		var err error
+		if len(b.Updates) == 1 {
+			err = s.SubmitPR(ctx, project, b.Updates[0])
+		} else {
+			err = s.dispatch(ctx, nomadJobIDPrefix+nomadManager(b.Updates[0]), batchMeta(project, b))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitByManager splits the batch into a batch per dependency manager, in the order of their
+// first update. Updates without a manager go with those of the default manager.
+func splitByManager(batch Batch) []Batch {
+	var (
+		batches []Batch
+		index   = map[string]int{}
+	)
+	for _, u := range batch.Updates {
+		manager := nomadManager(u)
+		i, ok := index[manager]
+		if !ok {
+			i = len(batches)
+			index[manager] = i
+			batches = append(batches, Batch{Name: batch.Name})
+		}
+		batches[i].Updates = append(batches[i].Updates, u)
+	}
+	return batches
+}
+
+// updateMeta returns the dispatch meta of a PR applying a single update.
+func updateMeta(project depmap.Project, u Update) map[string]string {
+	meta := map[string]string{
+		"PROJECT":    project.Name,
+		"GIT_REMOTE": project.GitURL,
+		"GIT_BRANCH": project.TargetBranch(),
+		"MANIFEST":   u.manifest(),
+		"MANAGER":    nomadManager(u),
+		"DEPENDENCY": u.Name,
+		"TOVERSION":  u.To,
+	}
+	if u.ReleaseNotes != "" {
+		meta["RELEASE_NOTES"] = u.ReleaseNotes
+	}
+	labels := []string{}
+	if project.Config != nil {
+		meta["REVIEWERS"] = strings.Join(project.Config.Reviewers, ",")
+		labels = append(labels, project.Config.Labels...)
+	}
+	if u.Prerelease || prerelease(u.To) != "" {
+		// prerelease PRs are marked so they are not merged by accident
+		meta["PRERELEASE"] = "true"
+		labels = append(labels, "prerelease")
+	}
+	if u.Migration {
+		// the job rewrites imports to the new module path instead of bumping the version
+		meta["MIGRATION"] = "true"
+		meta["MODULE_PATH"] = u.Path
+		labels = append(labels, "migration")
+	}
+	if u.Security {
+		meta["SECURITY"] = "true"
+		meta["ADVISORIES"] = strings.Join(u.Advisories, ",")
+		labels = append(labels, "security")
+	}
+	if u.Retracted {
+		// the module's authors ask to move off the current version, To may be a downgrade
+		meta["RETRACTED"] = "true"
+		meta["RETRACTION"] = u.Retraction
+		labels = append(labels, "retracted")
+	}
+	if u.Breaking() {
+		changes := make([]string, 0, len(u.APIChanges))
+		for _, c := range u.APIChanges {
+			changes = append(changes, c.String())
+		}
+		meta["BREAKING"] = "true"
+		meta["API_CHANGES"] = strings.Join(changes, "\n")
+		labels = append(labels, "breaking")
+	}
+	if u.LicenseChanged() {
+		// relicensed dependencies need a legal review before merging
+		meta["FROM_LICENSE"] = u.FromLicense
+		meta["TO_LICENSE"] = u.ToLicense
+		labels = append(labels, "license-change")
+	}
+	if len(labels) > 0 {
+		meta["LABELS"] = strings.Join(labels, ",")
+	}
+	return meta
+}
+
+// batchMeta returns the dispatch meta of a PR applying several updates. UPDATES lists the
+// manifest, dependency and version of each update, one per line, instead of MANIFEST,
+// DEPENDENCY and TOVERSION. Release notes are concatenated, flags are set when any update
+// sets them and labels are merged.
+func batchMeta(project depmap.Project, batch Batch) map[string]string {
+	meta := map[string]string{
 		"PROJECT":    project.Name,
 		"GIT_REMOTE": project.GitURL,
-		"GIT_BRANCH": project.Branch,
-		"DEPENDENCY": dependency,
-		"TOVERSION":  toversion,
-	}, nil, nil)
+		"GIT_BRANCH": project.TargetBranch(),
+		"BATCH":      batch.Name,
+	}
+	if len(batch.Updates) > 0 {
+		// batches are split by manager before they are dispatched
+		meta["MANAGER"] = nomadManager(batch.Updates[0])
+	}
+
+	var (
+		updates, notes, advisories, changes []string
+		labels                              []string
+		seenLabels                          = map[string]bool{}
+	)
+	for _, u := range batch.Updates {
+		m := updateMeta(project, u)
+		updates = append(updates, fmt.Sprintf("%s %s %s", m["MANIFEST"], m["DEPENDENCY"], m["TOVERSION"]))
+		if m["RELEASE_NOTES"] != "" {
+			notes = append(notes, fmt.Sprintf("## %s %s\n\n%s", u.Name, u.To, m["RELEASE_NOTES"]))
+		}
+		if m["ADVISORIES"] != "" {
+			advisories = append(advisories, m["ADVISORIES"])
+		}
+		if m["API_CHANGES"] != "" {
+			changes = append(changes, m["API_CHANGES"])
+		}
+		for _, key := range []string{"REVIEWERS", "PRERELEASE", "SECURITY", "RETRACTED", "BREAKING"} {
+			if v, ok := m[key]; ok {
+				meta[key] = v
+			}
+		}
+		if m["FROM_LICENSE"] != "" {
+			meta["LICENSE_CHANGE"] = "true"
+		}
+		for _, label := range strings.Split(m["LABELS"], ",") {
+			if label != "" && !seenLabels[label] {
+				seenLabels[label] = true
+				labels = append(labels, label)
+			}
+		}
+	}
+
+	meta["UPDATES"] = strings.Join(updates, "\n")
+	if len(notes) > 0 {
+		meta["RELEASE_NOTES"] = strings.Join(notes, "\n\n")
+	}
+	if len(advisories) > 0 {
+		meta["ADVISORIES"] = strings.Join(advisories, ",")
+	}
+	if len(changes) > 0 {
+		meta["API_CHANGES"] = strings.Join(changes, "\n")
+	}
+	if len(labels) > 0 {
+		meta["LABELS"] = strings.Join(labels, ",")
+	}
+	return meta
+}
+
+func (s *nomadSubmitter) dispatch(ctx context.Context, jobID string, meta map[string]string) error {
+	// QUESTION: does the nomad API not use context.Context?
+	resp, _, err := s.client.Jobs().Dispatch(jobID, meta, nil, nil)
 	if err != nil {
-		return errors.Wrapf(err, "unable to dispatch nomad job")
+		return errors.Wrapf(err, "unable to dispatch nomad job %s", jobID)
 	}
 
 	log.Printf("dispatching job %q", resp.DispatchedJobID)
@@ -0,0 +1,56 @@
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fresh/go-fresh/depmap"
+)
+
+func TestBatchMeta(t *testing.T) {
+	assert := require.New(t)
+
+	project := depmap.Project{
+		Name:   "example",
+		GitURL: "git@github.com:example/example.git",
+		Branch: "master",
+		Config: &depmap.Config{Labels: []string{"dependencies"}},
+	}
+	meta := batchMeta(project, Batch{Name: GroupAll, Updates: []Update{
+		{Name: "github.com/foo/bar", Manifest: "", To: "1.1.0", ReleaseNotes: "notes"},
+		{Name: "github.com/foo/baz", Manifest: "tools", To: "2.0.0-rc.1"},
+	}})
+
+	assert.Equal(map[string]string{
+		"PROJECT":       "example",
+		"GIT_REMOTE":    "git@github.com:example/example.git",
+		"GIT_BRANCH":    "master",
+		"BATCH":         GroupAll,
+		"MANAGER":       "govendor",
+		"UPDATES":       ". github.com/foo/bar 1.1.0\ntools github.com/foo/baz 2.0.0-rc.1",
+		"RELEASE_NOTES": "## github.com/foo/bar 1.1.0\n\nnotes",
+		"REVIEWERS":     "",
+		"PRERELEASE":    "true",
+		"LABELS":        "dependencies,prerelease",
+	}, meta)
+}
+
+func TestSplitByManager(t *testing.T) {
+	assert := require.New(t)
+
+	updates := []Update{
+		{Name: "github.com/foo/bar", Manager: "gomod"},
+		{Name: "github.com/foo/baz"},
+		{Name: "github.com/foo/qux", Manager: "gomod"},
+		{Name: "github.com/foo/quux", Manager: "govendor"},
+	}
+	batches := splitByManager(Batch{Name: GroupAll, Updates: updates})
+	assert.Equal([]Batch{
+		{Name: GroupAll, Updates: []Update{updates[0], updates[2]}},
+		{Name: GroupAll, Updates: []Update{updates[1], updates[3]}},
+	}, batches)
+
+	meta := updateMeta(depmap.Project{Name: "example"}, updates[0])
+	assert.Equal("gomod", meta["MANAGER"])
+}
@@ -0,0 +1,111 @@
+package updater
+
+import (
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+)
+
+// Policy controls which versions List proposes for a dependency. Each policy proposes the
+// newest version of every update type it includes, so a dependency can have both a patch
+// and a major update.
+type Policy string
+
+// Update policies.
+const (
+	// PolicyPatch proposes patch updates only.
+	PolicyPatch Policy = "patch"
+	// PolicyMinor proposes patch and minor updates.
+	PolicyMinor Policy = "minor"
+	// PolicyLatest proposes patch, minor and major updates.
+	PolicyLatest Policy = "latest"
+	// PolicyLatestInMajor proposes only the newest version within the current major version.
+	PolicyLatestInMajor Policy = "latest-in-major"
+)
+
+// ParsePolicy validates an update policy name, an empty name is PolicyLatest.
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(s); p {
+	case "":
+		return PolicyLatest, nil
+	case PolicyPatch, PolicyMinor, PolicyLatest, PolicyLatestInMajor:
+		return p, nil
+	}
+	return "", errors.Errorf("unknown update policy %q", s)
+}
+
+// allows reports if the policy includes updates of the type. Updates of an unknown type,
+// where the current version is not a semver version, are always included.
+func (p Policy) allows(updateType string) bool {
+	switch updateType {
+	case "", UpdatePatch:
+		return true
+	case UpdateMinor:
+		return p != PolicyPatch
+	case UpdateMajor:
+		return p == PolicyLatest
+	}
+	return false
+}
+
+// candidates returns the versions the policy proposes from the sorted versions. isNewer
+// reports if a version is an update from the current version.
+func (p Policy) candidates(sorted []semver.Version, current *semver.Version, isNewer func(semver.Version) bool) []semver.Version {
+	newest := func(match func(semver.Version) bool) (semver.Version, bool) {
+		for i := len(sorted) - 1; i >= 0; i-- {
+			if match(sorted[i]) {
+				return sorted[i], isNewer(sorted[i])
+			}
+		}
+		return semver.Version{}, false
+	}
+
+	if current == nil {
+		// without a current version there are no tiers, only the newest version
+		v, ok := newest(func(semver.Version) bool { return true })
+		if !ok {
+			return nil
+		}
+		return []semver.Version{v}
+	}
+
+	sameMajor := func(v semver.Version) bool {
+		return v.Major() == current.Major()
+	}
+
+	if p == PolicyLatestInMajor {
+		v, ok := newest(sameMajor)
+		if !ok {
+			return nil
+		}
+		return []semver.Version{v}
+	}
+
+	candidates := []semver.Version{}
+
+	patch, ok := newest(func(v semver.Version) bool {
+		return sameMajor(v) && v.Minor() == current.Minor()
+	})
+	if ok {
+		candidates = append(candidates, patch)
+	}
+
+	if p.allows(UpdateMinor) {
+		minor, ok := newest(func(v semver.Version) bool {
+			return sameMajor(v) && v.Minor() != current.Minor()
+		})
+		if ok && minor.GreaterThan(*current) {
+			candidates = append(candidates, minor)
+		}
+	}
+
+	if p.allows(UpdateMajor) {
+		major, ok := newest(func(v semver.Version) bool {
+			return v.Major() > current.Major()
+		})
+		if ok {
+			candidates = append(candidates, major)
+		}
+	}
+
+	return candidates
+}
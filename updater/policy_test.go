@@ -0,0 +1,62 @@
+package updater
+
+import (
+	"testing"
+
+	"github.com/golang/dep/gps"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fresh/go-fresh/depmap"
+)
+
+func TestVersionUpdates_Policy(t *testing.T) {
+	raw := pairedVersions("v1.0.0", "v1.0.1", "v1.0.2", "v1.1.0", "v1.2.0", "v2.0.0", "v2.1.0")
+	dep := depmap.Dependency{Name: "example.com/foo", Revision: "rev-v1.0.0"}
+
+	for _, c := range []struct {
+		policy   Policy
+		expected []string
+	}{
+		{PolicyPatch, []string{"1.0.2"}},
+		{PolicyMinor, []string{"1.0.2", "1.2.0"}},
+		{PolicyLatest, []string{"1.0.2", "1.2.0", "2.1.0"}},
+		{PolicyLatestInMajor, []string{"1.2.0"}},
+	} {
+		t.Run(string(c.policy), func(t *testing.T) {
+			assert := require.New(t)
+
+			updates, err := versionUpdates(gps.ProjectIdentifier{ProjectRoot: "example.com/foo"}, []depmap.Dependency{dep}, raw, nil, &Options{Policy: c.policy})
+			assert.NoError(err)
+
+			actual := []string{}
+			for _, u := range updates {
+				assert.Equal(UpdateType(u.From, u.To), u.Bump)
+				actual = append(actual, u.To)
+			}
+			assert.Equal(c.expected, actual)
+		})
+	}
+}
+
+func TestOptionsPolicy(t *testing.T) {
+	assert := require.New(t)
+
+	opts := &Options{
+		Policy: PolicyMinor,
+		Config: &depmap.Config{
+			Policy:   "latest",
+			Policies: map[string]string{"example.com/foo": "patch"},
+		},
+	}
+	assert.Equal("patch", opts.policy("example.com/foo/bar"))
+	assert.Equal("latest", opts.policy("example.com/other"))
+
+	opts.Config = nil
+	assert.Equal("minor", opts.policy("example.com/other"))
+
+	_, err := ParsePolicy("newest")
+	assert.Error(err)
+	p, err := ParsePolicy("")
+	assert.NoError(err)
+	assert.Equal(PolicyLatest, p)
+}
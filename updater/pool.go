@@ -0,0 +1,90 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DependencyError is a failure to find the updates of a single dependency.
+type DependencyError struct {
+	Name string
+	Err  error
+}
+
+func (e *DependencyError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Name, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *DependencyError) Unwrap() error {
+	return e.Err
+}
+
+// ListError collects the dependencies List failed to find updates for.
+type ListError []*DependencyError
+
+func (e ListError) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("unable to list updates of %d dependencies: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// parallel calls fn for each index below n on at most workers goroutines. No new calls are
+// started once ctx is done.
+func parallel(ctx context.Context, workers, n int, fn func(i int)) {
+	if workers > n {
+		workers = n
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// withTimeout runs fn with a context limited by the timeout, returning early when ctx is done or the timeout passes. gps does not
+// take a context, so an abandoned call keeps running in the background until it returns.
+func withTimeout(ctx context.Context, timeout time.Duration, fn func(ctx context.Context) error) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "gave up waiting for source")
+	}
+}
@@ -0,0 +1,170 @@
+package updater
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/golang/dep/gps"
+	"github.com/pkg/errors"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+
+	"github.com/go-fresh/go-fresh/depmap"
+)
+
+// RetractionSource reads the versions the authors of Go modules retracted.
+type RetractionSource interface {
+	// Retractions returns the retractions of the go.mod declaring modulePath at the revision of
+	// the repository at gitURL. dirs are the directories the go.mod may be in, the first found
+	// declaring the module is read. No retractions are returned when none is.
+	Retractions(ctx context.Context, gitURL, branch, revision, modulePath string, dirs []string) ([]depmap.Retraction, error)
+}
+
+// Retractions implements RetractionSource.
+func (h *GitHistory) Retractions(ctx context.Context, gitURL, branch, revision, modulePath string, dirs []string) ([]depmap.Retraction, error) {
+	repo, release, err := h.open(ctx, gitURL, branch)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return revisionRetractions(repo, revision, modulePath, dirs)
+}
+
+func revisionRetractions(repo *git.Repository, revision, modulePath string, dirs []string) ([]depmap.Retraction, error) {
+	tree, err := revisionTree(repo, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dir := range dirs {
+		name := path.Join(dir, "go.mod")
+		f, err := tree.File(name)
+		if err == object.ErrFileNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read %s at %s", name, revision)
+		}
+		raw, err := f.Contents()
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read %s at %s", name, revision)
+		}
+
+		path, retractions, err := depmap.ParseRetractions(name, []byte(raw))
+		if err != nil {
+			return nil, err
+		}
+		if path == modulePath {
+			return retractions, nil
+		}
+	}
+	return nil, nil
+}
+
+// retraction returns the retraction covering the version.
+func retraction(retractions []depmap.Retraction, v semver.Version) (depmap.Retraction, bool) {
+	for _, r := range retractions {
+		if r.Retracts(v.String()) {
+			return r, true
+		}
+	}
+	return depmap.Retraction{}, false
+}
+
+// withoutRetracted returns the versions that were not retracted.
+func withoutRetracted(retractions []depmap.Retraction, vs []semver.Version) []semver.Version {
+	if len(retractions) == 0 {
+		return vs
+	}
+	kept := make([]semver.Version, 0, len(vs))
+	for _, v := range vs {
+		if _, ok := retraction(retractions, v); !ok {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// readRetractions reads the retractions of the module dependencies of the project, keyed by
+// dependency name. As the go command does, they are read from the go.mod of the module's
+// latest release. Dependencies whose retractions cannot be read are left out and their errors
+// returned.
+func readRetractions(ctx context.Context, smgr sourceManager, id gps.ProjectIdentifier, deps []depmap.Dependency, raw []gps.PairedVersion, opts *Options) (map[string][]depmap.Retraction, []*DependencyError) {
+	var (
+		gitURL      string
+		retractions = map[string][]depmap.Retraction{}
+		errs        []*DependencyError
+	)
+	for _, dep := range deps {
+		mod, ok := parseModulePath(dep)
+		if !ok {
+			continue
+		}
+		if _, ok := retractions[dep.Name]; ok {
+			continue
+		}
+
+		latest, ok := latestModuleVersion(mod, raw)
+		if !ok {
+			continue
+		}
+
+		if gitURL == "" {
+			url, err := sourceURL(smgr, id)
+			if err != nil {
+				errs = append(errs, &DependencyError{Name: dep.Name, Err: err})
+				continue
+			}
+			gitURL = url
+		}
+
+		var rs []depmap.Retraction
+		err := withTimeout(ctx, opts.Timeout, func(ctx context.Context) (err error) {
+			rs, err = opts.Retractions.Retractions(ctx, gitURL, "", latest.Revision().String(), dep.Name, moduleDirs(id, mod, dep))
+			return err
+		})
+		if err != nil {
+			errs = append(errs, &DependencyError{
+				Name: dep.Name,
+				Err:  errors.Wrapf(err, "unable to read retractions of %s", dep.Name),
+			})
+			continue
+		}
+		retractions[dep.Name] = rs
+	}
+	return retractions, errs
+}
+
+// latestModuleVersion returns the newest non-prerelease version of the module's path.
+func latestModuleVersion(mod modulePath, raw []gps.PairedVersion) (gps.PairedVersion, bool) {
+	inPath := make([]gps.PairedVersion, 0, len(raw))
+	for _, r := range raw {
+		v, err := semver.NewVersion(r.String())
+		if err == nil && r.Type() != gps.IsBranch && mod.inPath(v) {
+			inPath = append(inPath, r)
+		}
+	}
+	_, latest, ok := latestVersion(inPath)
+	return latest, ok
+}
+
+// moduleDirs returns the directories of the repository the module's go.mod may be in: the module
+// path below the project root, or that path without its major version suffix for modules
+// developed on the repository's main branch.
+func moduleDirs(id gps.ProjectIdentifier, mod modulePath, dep depmap.Dependency) []string {
+	rel := func(p string) string {
+		dir := strings.TrimPrefix(strings.TrimPrefix(p, string(id.ProjectRoot)), "/")
+		if dir == "" || dir == p {
+			return "."
+		}
+		return dir
+	}
+
+	dirs := []string{rel(dep.Name)}
+	if prefix := rel(mod.prefix); prefix != dirs[0] {
+		dirs = append(dirs, prefix)
+	}
+	return dirs
+}
@@ -0,0 +1,144 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/dep/gps"
+	"github.com/stretchr/testify/require"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+
+	"github.com/go-fresh/go-fresh/depmap"
+)
+
+func TestVersionUpdates_Retracted(t *testing.T) {
+	raw := pairedVersions("v1.0.0", "v1.1.0", "v1.2.0", "v2.0.0")
+	retractions := map[string][]depmap.Retraction{
+		"github.com/foo/bar": {{Low: "v1.2.0", High: "v1.2.0", Rationale: "broken build"}},
+	}
+
+	for i, c := range []struct {
+		expected []Update
+		dep      depmap.Dependency
+	}{
+		{
+			// retracted versions are not proposed
+			[]Update{
+				{Name: "github.com/foo/bar", ProjectRoot: "github.com/foo/bar", Revision: "rev-v1.1.0", From: "1.0.0", FromRevision: "rev-v1.0.0", To: "1.1.0", Bump: UpdateMinor},
+				{Name: "github.com/foo/bar", ProjectRoot: "github.com/foo/bar", Revision: "rev-v2.0.0", From: "1.0.0", FromRevision: "rev-v1.0.0", To: "2.0.0", Bump: UpdateMajor, Migration: true, Path: "github.com/foo/bar/v2"},
+			},
+			depmap.Dependency{Name: "github.com/foo/bar", Revision: "v1.0.0", Version: "v1.0.0", Module: true},
+		},
+		{
+			// moving off the retracted version, to an older one
+			[]Update{
+				{Name: "github.com/foo/bar", ProjectRoot: "github.com/foo/bar", Revision: "rev-v2.0.0", From: "1.2.0", FromRevision: "rev-v1.2.0", To: "2.0.0", Bump: UpdateMajor, Migration: true, Path: "github.com/foo/bar/v2"},
+				{Name: "github.com/foo/bar", ProjectRoot: "github.com/foo/bar", Revision: "rev-v1.1.0", From: "1.2.0", FromRevision: "rev-v1.2.0", To: "1.1.0", Bump: UpdateMinor, Retracted: true, Retraction: "broken build"},
+			},
+			depmap.Dependency{Name: "github.com/foo/bar", Revision: "v1.2.0", Version: "v1.2.0", Module: true},
+		},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert := require.New(t)
+
+			actual, err := versionUpdates(gps.ProjectIdentifier{ProjectRoot: "github.com/foo/bar"}, []depmap.Dependency{c.dep}, raw, retractions, &Options{})
+			assert.NoError(err)
+			assert.Equal(c.expected, actual)
+		})
+	}
+}
+
+func TestVersionUpdates_RetractedUpdate(t *testing.T) {
+	assert := require.New(t)
+
+	raw := pairedVersions("v1.0.0", "v1.1.0")
+	retractions := map[string][]depmap.Retraction{
+		"github.com/foo/bar": {{Low: "v1.0.0", High: "v1.0.0", Rationale: "tag was moved"}},
+	}
+	dep := depmap.Dependency{Name: "github.com/foo/bar", Revision: "v1.0.0", Version: "v1.0.0", Module: true}
+
+	actual, err := versionUpdates(gps.ProjectIdentifier{ProjectRoot: "github.com/foo/bar"}, []depmap.Dependency{dep}, raw, retractions, &Options{})
+	assert.NoError(err)
+	assert.Equal([]Update{
+		{Name: "github.com/foo/bar", ProjectRoot: "github.com/foo/bar", Revision: "rev-v1.1.0", From: "1.0.0", FromRevision: "rev-v1.0.0", To: "1.1.0", Bump: UpdateMinor, Retracted: true, Retraction: "tag was moved"},
+	}, actual)
+
+	// moving off a retracted version is not subject to update policies
+	conf := &depmap.Config{Policy: "patch"}
+	assert.False(Allowed(conf, Update{Name: dep.Name, From: "1.0.0", To: "1.1.0"}))
+	assert.True(Allowed(conf, actual[0]))
+}
+
+type fakeRetractionSource struct {
+	revision string
+	dirs     []string
+}
+
+func (s *fakeRetractionSource) Retractions(ctx context.Context, gitURL, branch, revision, modulePath string, dirs []string) ([]depmap.Retraction, error) {
+	s.revision, s.dirs = revision, dirs
+	return []depmap.Retraction{{Low: "v2.1.0", High: "v2.1.0"}}, nil
+}
+
+func TestListUpdates_Retractions(t *testing.T) {
+	assert := require.New(t)
+
+	smgr := &fakeSourceManager{
+		versions: map[gps.ProjectRoot][]gps.PairedVersion{
+			"example.com/foo/bar": pairedVersions("v1.0.0", "v2.0.0", "v2.1.0", "v2.2.0-rc.1"),
+		},
+	}
+	deps := []depmap.Dependency{
+		{Name: "example.com/foo/bar/v2", Revision: "v2.0.0", Version: "v2.0.0", Module: true},
+	}
+	source := &fakeRetractionSource{}
+
+	updates, err := listUpdates(context.Background(), smgr, deps, &Options{Retractions: source})
+	assert.NoError(err)
+	assert.Empty(updates)
+	assert.Equal("rev-v2.1.0", source.revision)
+	assert.Equal([]string{"v2", "."}, source.dirs)
+}
+
+func TestRevisionRetractions(t *testing.T) {
+	assert := require.New(t)
+
+	tmp, err := ioutil.TempDir("", "")
+	assert.NoError(err)
+	defer os.RemoveAll(tmp)
+
+	repo, err := git.PlainInit(tmp, false)
+	assert.NoError(err)
+	tree, err := repo.Worktree()
+	assert.NoError(err)
+
+	assert.NoError(ioutil.WriteFile(filepath.Join(tmp, "go.mod"), []byte("module github.com/foo/bar\n\nretract v1.0.0 // old\n"), 0644))
+	assert.NoError(os.Mkdir(filepath.Join(tmp, "v2"), 0755))
+	assert.NoError(ioutil.WriteFile(filepath.Join(tmp, "v2", "go.mod"), []byte("module github.com/foo/bar/v2\n\nretract [v2.0.0, v2.0.2] // broken build\n"), 0644))
+	_, err = tree.Add("go.mod")
+	assert.NoError(err)
+	_, err = tree.Add("v2/go.mod")
+	assert.NoError(err)
+
+	sig := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	hash, err := tree.Commit("modules", &git.CommitOptions{Author: sig, Committer: sig})
+	assert.NoError(err)
+
+	retractions, err := revisionRetractions(repo, hash.String(), "github.com/foo/bar/v2", []string{"v2", "."})
+	assert.NoError(err)
+	assert.Equal([]depmap.Retraction{{Low: "v2.0.0", High: "v2.0.2", Rationale: "broken build"}}, retractions)
+
+	retractions, err = revisionRetractions(repo, hash.String(), "github.com/foo/bar", []string{"."})
+	assert.NoError(err)
+	assert.Equal([]depmap.Retraction{{Low: "v1.0.0", High: "v1.0.0", Rationale: "old"}}, retractions)
+
+	// no go.mod declares the module
+	retractions, err = revisionRetractions(repo, hash.String(), "github.com/foo/bar/v3", []string{"v3", "."})
+	assert.NoError(err)
+	assert.Empty(retractions)
+}
@@ -0,0 +1,95 @@
+package updater
+
+import (
+	"sort"
+
+	"github.com/Masterminds/semver"
+
+	"github.com/go-fresh/go-fresh/depmap"
+	"github.com/go-fresh/go-fresh/vuln"
+)
+
+// securityFix returns the lowest newer version fixing every advisory with a released fix that
+// affects the dependency at its current version, along with the IDs of the advisories it fixes.
+// Versions still affected by such an advisory, or affected by another advisory, are skipped.
+// ancestry matches dependencies without a version to advisories by their revision, it may be nil.
+func securityFix(db *vuln.Database, ancestry vuln.Ancestry, dep depmap.Dependency, current *semver.Version, sorted []semver.Version, isNewer func(semver.Version) bool) (semver.Version, []string, bool) {
+	if current != nil {
+		dep.Version = current.String()
+	}
+
+	findings := db.AffectingWith(dep, ancestry)
+	if len(findings) == 0 {
+		return semver.Version{}, nil, false
+	}
+
+	// advisories without a released fix affect every candidate, and are left to be fixed later
+	unfixed := map[string]bool{}
+	for _, f := range findings {
+		if _, err := semver.NewVersion(f.Fixed); err != nil {
+			unfixed[f.Advisory.ID] = true
+		}
+	}
+
+	for _, v := range sorted {
+		if v.Prerelease() != "" || !isNewer(v) {
+			continue
+		}
+
+		remaining := map[string]bool{}
+		for _, f := range db.Affecting(depmap.Dependency{Name: dep.Name, Version: v.String()}) {
+			remaining[f.Advisory.ID] = true
+		}
+		ids := []string{}
+		acceptable := true
+		for id := range remaining {
+			if !unfixed[id] {
+				acceptable = false
+				break
+			}
+		}
+		if !acceptable {
+			continue
+		}
+		for _, f := range findings {
+			if !remaining[f.Advisory.ID] {
+				ids = append(ids, f.Advisory.ID)
+			}
+		}
+		if len(ids) > 0 {
+			return v, ids, true
+		}
+	}
+	return semver.Version{}, nil, false
+}
+
+// MarkSecurity marks the update of the dependency as a security update when its version fixes
+// every advisory affecting the dependency's current version, or revision. db and ancestry may
+// be nil.
+func MarkSecurity(db *vuln.Database, ancestry vuln.Ancestry, dep depmap.Dependency, u Update) Update {
+	to, err := semver.NewVersion(u.To)
+	if err != nil || u.Migration {
+		// migrations are not fixes of the current module path
+		return u
+	}
+
+	var current *semver.Version
+	if v, err := semver.NewVersion(dep.Version); err == nil {
+		current = &v
+	}
+	isNewer := func(v semver.Version) bool {
+		return current == nil || v.GreaterThan(*current)
+	}
+
+	if _, ids, ok := securityFix(db, ancestry, dep, current, []semver.Version{to}, isNewer); ok {
+		u.Security, u.Advisories = true, ids
+	}
+	return u
+}
+
+// Prioritize orders security updates before routine updates, keeping the order otherwise.
+func Prioritize(updates []Update) {
+	sort.SliceStable(updates, func(i, j int) bool {
+		return updates[i].Security && !updates[j].Security
+	})
+}
@@ -0,0 +1,166 @@
+package updater
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Masterminds/semver"
+	"github.com/golang/dep/gps"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fresh/go-fresh/depmap"
+	"github.com/go-fresh/go-fresh/vuln"
+)
+
+func TestVersionUpdates_Security(t *testing.T) {
+	raw := pairedVersions("v1.0.0", "v1.2.0", "v1.2.1", "v1.3.0")
+	opts := &Options{
+		Policy: PolicyPatch,
+		Advisories: &vuln.Database{Advisories: []*vuln.Advisory{
+			{
+				ID: "GO-2018-0001",
+				Affected: []vuln.Affected{{
+					Package: vuln.Package{Ecosystem: "Go", Name: "example.com/foo"},
+					Ranges: []vuln.Range{{
+						Type:   vuln.RangeSemver,
+						Events: []vuln.Event{{Introduced: "0"}, {Fixed: "1.2.1"}},
+					}},
+				}},
+			},
+		}},
+	}
+
+	for i, c := range []struct {
+		expected []Update
+		dep      depmap.Dependency
+	}{
+		{
+			[]Update{
+				{Name: "example.com/foo", ProjectRoot: "example.com/foo", Revision: "rev-v1.2.1", From: "1.2.0", FromRevision: "rev-v1.2.0", To: "1.2.1", Bump: UpdatePatch, Security: true, Advisories: []string{"GO-2018-0001"}},
+			},
+			depmap.Dependency{Name: "example.com/foo", Revision: "rev-v1.2.0"},
+		},
+		{
+			[]Update{
+				{Name: "example.com/foo", ProjectRoot: "example.com/foo", Revision: "rev-v1.2.1", From: "1.0.0", FromRevision: "rev-v1.0.0", To: "1.2.1", Bump: UpdateMinor, Security: true, Advisories: []string{"GO-2018-0001"}},
+			},
+			depmap.Dependency{Name: "example.com/foo", Revision: "rev-v1.0.0"},
+		},
+		{
+			[]Update{},
+			depmap.Dependency{Name: "example.com/foo", Revision: "rev-v1.3.0"},
+		},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert := require.New(t)
+
+			actual, err := versionUpdates(gps.ProjectIdentifier{ProjectRoot: "example.com/foo"}, []depmap.Dependency{c.dep}, raw, nil, opts)
+			assert.NoError(err)
+			assert.Equal(c.expected, actual)
+		})
+	}
+}
+
+func TestSecurityFix(t *testing.T) {
+	assert := require.New(t)
+
+	advisory := func(id string, events ...vuln.Event) *vuln.Advisory {
+		return &vuln.Advisory{
+			ID: id,
+			Affected: []vuln.Affected{{
+				Package: vuln.Package{Ecosystem: "Go", Name: "example.com/foo"},
+				Ranges:  []vuln.Range{{Type: vuln.RangeSemver, Events: events}},
+			}},
+		}
+	}
+	db := &vuln.Database{Advisories: []*vuln.Advisory{
+		advisory("GO-2018-0001", vuln.Event{Introduced: "0"}, vuln.Event{Fixed: "1.2.1"}),
+		// introduced by the first fix
+		advisory("GO-2018-0002", vuln.Event{Introduced: "1.2.1"}, vuln.Event{Fixed: "1.3.0"}),
+		// not fixed yet
+		advisory("GO-2018-0003", vuln.Event{Introduced: "0"}),
+	}}
+
+	current := mustVersion(t, "1.2.0")
+	sorted := []semver.Version{mustVersion(t, "1.2.0"), mustVersion(t, "1.2.1"), mustVersion(t, "1.3.0")}
+	isNewer := func(v semver.Version) bool { return v.GreaterThan(current) }
+
+	fix, ids, ok := securityFix(db, nil, depmap.Dependency{Name: "example.com/foo"}, &current, sorted, isNewer)
+	assert.True(ok)
+	assert.Equal("1.3.0", fix.String())
+	assert.Equal([]string{"GO-2018-0001"}, ids)
+
+	// nothing to fix while only the unfixed advisory affects the dependency
+	current = mustVersion(t, "1.3.0")
+	_, _, ok = securityFix(db, nil, depmap.Dependency{Name: "example.com/foo"}, &current, sorted, isNewer)
+	assert.False(ok)
+}
+
+func TestPrioritize(t *testing.T) {
+	updates := []Update{
+		{Name: "a"},
+		{Name: "b", Security: true},
+		{Name: "c"},
+		{Name: "d", Security: true},
+	}
+	Prioritize(updates)
+
+	names := []string{}
+	for _, u := range updates {
+		names = append(names, u.Name)
+	}
+	require.Equal(t, []string{"b", "d", "a", "c"}, names)
+}
+
+type linearAncestry []string
+
+func (h linearAncestry) IsAncestor(repo, ancestor, descendant string) (bool, error) {
+	a, d := -1, -1
+	for i, c := range h {
+		if c == ancestor {
+			a = i
+		}
+		if c == descendant {
+			d = i
+		}
+	}
+	return a >= 0 && d >= 0 && a <= d, nil
+}
+
+func TestMarkSecurity(t *testing.T) {
+	db := &vuln.Database{Advisories: []*vuln.Advisory{
+		{
+			ID: "GO-2018-0001",
+			Affected: []vuln.Affected{{
+				Package: vuln.Package{Ecosystem: "Go", Name: "example.com/foo"},
+				Ranges: []vuln.Range{
+					{Type: vuln.RangeSemver, Events: []vuln.Event{{Introduced: "0"}, {Fixed: "1.2.1"}}},
+					{Type: vuln.RangeGit, Events: []vuln.Event{{Introduced: "aaa"}, {Fixed: "ccc"}}},
+				},
+			}},
+		},
+	}}
+	ancestry := linearAncestry{"aaa", "bbb", "ccc"}
+
+	for i, c := range []struct {
+		security bool
+		dep      depmap.Dependency
+		to       string
+	}{
+		{true, depmap.Dependency{Name: "example.com/foo", Version: "v1.2.0"}, "1.2.1"},
+		{true, depmap.Dependency{Name: "example.com/foo", Version: "v1.0.0"}, "1.3.0"},
+		{false, depmap.Dependency{Name: "example.com/foo", Version: "v1.0.0"}, "1.2.0"},
+		{false, depmap.Dependency{Name: "example.com/foo", Version: "v1.2.1"}, "1.3.0"},
+		// locked to a commit within the advisory's range
+		{true, depmap.Dependency{Name: "example.com/foo", Revision: "bbb"}, "1.2.1"},
+		{false, depmap.Dependency{Name: "example.com/foo", Revision: "ccc"}, "1.2.1"},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			u := MarkSecurity(db, ancestry, c.dep, Update{Name: c.dep.Name, To: c.to})
+			require.Equal(t, c.security, u.Security)
+		})
+	}
+
+	// without advisories nothing is a security update
+	require.False(t, MarkSecurity(nil, nil, depmap.Dependency{Name: "example.com/foo", Version: "v1.0.0"}, Update{To: "1.2.1"}).Security)
+}
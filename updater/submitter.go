@@ -2,14 +2,20 @@ package updater
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"strings"
 
 	"github.com/go-fresh/go-fresh/depmap"
 )
 
 // Submitter represents an implementation that can SubmitPR's
 type Submitter interface {
-	SubmitPR(ctx context.Context, project depmap.Project, dependency, toversion string) error
+	// SubmitPR submits a PR to the project applying the update in the update's manifest directory.
+	SubmitPR(ctx context.Context, project depmap.Project, u Update) error
+
+	// SubmitBatch submits a single PR to the project applying all of the batch's updates.
+	SubmitBatch(ctx context.Context, project depmap.Project, batch Batch) error
 }
 
 type logOnlySubmitter struct{}
@@ -18,7 +24,65 @@ func NewLogOnlySubmitter() Submitter {
 	return &logOnlySubmitter{}
 }
 
-func (s *logOnlySubmitter) SubmitPR(ctx context.Context, project depmap.Project, dependency, toversion string) error {
-	log.Printf("submit PR for %s, update %s to %s", project.Name, dependency, toversion)
+func (s *logOnlySubmitter) SubmitPR(ctx context.Context, project depmap.Project, u Update) error {
+	log.Printf("submit %s for %s (%s) to %s, %s", updateKind(u), project.Name, u.manifest(), project.TargetBranch(), updateSummary(u))
+	logDetails(u)
+	return nil
+}
+
+func (s *logOnlySubmitter) SubmitBatch(ctx context.Context, project depmap.Project, batch Batch) error {
+	if len(batch.Updates) == 1 {
+		return s.SubmitPR(ctx, project, batch.Updates[0])
+	}
+
+	log.Printf("submit PR for %s to %s, %d updates of %s", project.Name, project.TargetBranch(), len(batch.Updates), batch.Name)
+	for _, u := range batch.Updates {
+		log.Printf("  %s (%s), %s", updateKind(u), u.manifest(), updateSummary(u))
+		logDetails(u)
+	}
 	return nil
 }
+
+// updateKind describes the kind of PR an update needs.
+func updateKind(u Update) string {
+	kind := "PR"
+	if u.Prerelease || prerelease(u.To) != "" {
+		kind = "prerelease PR"
+	}
+	if u.Security {
+		kind = fmt.Sprintf("security %s (%s)", kind, strings.Join(u.Advisories, ", "))
+	}
+	if u.Retracted {
+		kind = "retracted version " + kind
+	}
+	if u.Breaking() {
+		kind = "breaking " + kind
+	}
+	if u.Migration {
+		kind = "path migration " + kind
+	}
+	return kind
+}
+
+// updateSummary describes the change an update makes.
+func updateSummary(u Update) string {
+	if u.Migration {
+		return fmt.Sprintf("rewrite imports of %s to %s at %s", u.Name, u.Path, u.To)
+	}
+	return fmt.Sprintf("update %s to %s", u.Name, u.To)
+}
+
+func logDetails(u Update) {
+	if u.Retracted {
+		log.Printf("%s %s was retracted: %s", u.Name, u.From, u.Retraction)
+	}
+	for _, c := range u.APIChanges {
+		log.Printf("breaking API change: %s", c)
+	}
+	if u.LicenseChanged() {
+		log.Printf("license of %s changes from %s to %s", u.Name, u.FromLicense, u.ToLicense)
+	}
+	if u.ReleaseNotes != "" {
+		log.Printf("release notes:\n%s", u.ReleaseNotes)
+	}
+}
@@ -0,0 +1,155 @@
+package updater
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/dep/gps"
+	"github.com/pkg/errors"
+
+	"github.com/go-fresh/go-fresh/depmap"
+)
+
+// TagStore records the tags of dependency sources between scans.
+type TagStore interface {
+	// Tags returns the revision of each tag recorded for the source, or nil if none were.
+	Tags(source string) (map[string]string, error)
+
+	// RecordTags replaces the tags recorded for the source.
+	RecordTags(source string, tags map[string]string) error
+}
+
+// Tag changes.
+const (
+	// TagMoved tags point to another revision than when they were recorded.
+	TagMoved = "moved"
+	// TagDeleted tags no longer exist.
+	TagDeleted = "deleted"
+)
+
+// TagChange is a change to the tag a dependency is on since it was locked or recorded, it is
+// reported as the error of the dependency.
+type TagChange struct {
+	Tag  string
+	Kind string
+
+	// Revision is the locked or recorded revision of the tag, Current the revision it points
+	// to now, empty when the tag was deleted.
+	Revision string
+	Current  string
+}
+
+func (c *TagChange) Error() string {
+	if c.Kind == TagDeleted {
+		return fmt.Sprintf("tag %s at %s was deleted", c.Tag, c.Revision)
+	}
+	return fmt.Sprintf("tag %s moved from %s to %s", c.Tag, c.Revision, c.Current)
+}
+
+// sourceTags returns the revision of each tag of a source.
+func sourceTags(raw []gps.PairedVersion) map[string]string {
+	tags := map[string]string{}
+	for _, r := range raw {
+		if r.Type() == gps.IsBranch {
+			continue
+		}
+		tags[r.String()] = r.Revision().String()
+	}
+	return tags
+}
+
+// checkTags reports the dependencies on a tag that moved or was deleted. Dependencies locked to a
+// commit are checked against it, others against the tag's revision recorded by a previous scan.
+// Recorded revisions are kept as they were first seen, tags are not supposed to move, so the
+// change is reported on every scan of every project on the tag until the dependency moves off it.
+// A recorded revision is only replaced once a dependency is locked to the tag's new commit.
+func checkTags(store TagStore, id gps.ProjectIdentifier, deps []depmap.Dependency, raw []gps.PairedVersion) []*DependencyError {
+	source := id.Source
+	if source == "" {
+		source = string(id.ProjectRoot)
+	}
+
+	recorded, err := store.Tags(source)
+	if err != nil {
+		err = errors.Wrapf(err, "unable to read recorded tags of %s", source)
+		errs := make([]*DependencyError, 0, len(deps))
+		for _, dep := range deps {
+			errs = append(errs, &DependencyError{Name: dep.Name, Err: err})
+		}
+		return errs
+	}
+
+	current := sourceTags(raw)
+	record := map[string]string{}
+	for tag, revision := range recorded {
+		record[tag] = revision
+	}
+	for tag, revision := range current {
+		if _, ok := record[tag]; !ok {
+			record[tag] = revision
+		}
+	}
+
+	var errs []*DependencyError
+	for _, dep := range deps {
+		tag, ok := dependencyTag(dep, current, recorded)
+		if !ok {
+			continue
+		}
+
+		expected := recorded[tag]
+		if isCommitHash(dep.Revision) {
+			// the lock is authoritative about the commit the tag pointed to
+			expected = dep.Revision
+			if current[tag] == dep.Revision {
+				record[tag] = dep.Revision
+			}
+		}
+
+		revision, exists := current[tag]
+		switch {
+		case !exists:
+			errs = append(errs, &DependencyError{Name: dep.Name, Err: &TagChange{Tag: tag, Kind: TagDeleted, Revision: expected}})
+		case expected != "" && revision != expected:
+			errs = append(errs, &DependencyError{Name: dep.Name, Err: &TagChange{Tag: tag, Kind: TagMoved, Revision: expected, Current: revision}})
+		}
+	}
+
+	if err := store.RecordTags(source, record); err != nil {
+		err = errors.Wrapf(err, "unable to record tags of %s", source)
+		for _, dep := range deps {
+			errs = append(errs, &DependencyError{Name: dep.Name, Err: err})
+		}
+	}
+	return errs
+}
+
+// dependencyTag returns the tag the dependency is on, among the current and the recorded tags.
+func dependencyTag(dep depmap.Dependency, current, recorded map[string]string) (string, bool) {
+	version := strings.TrimSuffix(dep.Version, "+incompatible")
+	for _, tag := range []string{version, "v" + version, dep.Revision} {
+		if tag == "" || tag == "v" {
+			continue
+		}
+		if _, ok := current[tag]; ok {
+			return tag, true
+		}
+		if _, ok := recorded[tag]; ok {
+			return tag, true
+		}
+	}
+	return "", false
+}
+
+// isCommitHash reports if the revision is a full git commit hash.
+func isCommitHash(revision string) bool {
+	if len(revision) != 40 {
+		return false
+	}
+	for _, c := range revision {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
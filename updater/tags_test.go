@@ -0,0 +1,82 @@
+package updater
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/dep/gps"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fresh/go-fresh/depmap"
+)
+
+type fakeTagStore map[string]map[string]string
+
+func (s fakeTagStore) Tags(source string) (map[string]string, error) {
+	return s[source], nil
+}
+
+func (s fakeTagStore) RecordTags(source string, tags map[string]string) error {
+	s[source] = tags
+	return nil
+}
+
+func TestListUpdates_Tags(t *testing.T) {
+	assert := require.New(t)
+
+	const (
+		original = "1111111111111111111111111111111111111111"
+		moved    = "2222222222222222222222222222222222222222"
+	)
+
+	smgr := &fakeSourceManager{
+		versions: map[gps.ProjectRoot][]gps.PairedVersion{
+			"example.com/foo/bar": {
+				gps.NewVersion("v1.0.0").Pair(gps.Revision(original)),
+			},
+			"example.com/foo/baz": pairedVersions("v1.0.0"),
+		},
+	}
+	onTag := []depmap.Dependency{{Name: "example.com/foo/bar", Revision: "v1.0.0", Version: "v1.0.0", Module: true}}
+	store := fakeTagStore{}
+
+	list := func(deps ...depmap.Dependency) []*DependencyError {
+		_, err := listUpdates(context.Background(), smgr, deps, &Options{Tags: store})
+		if err == nil {
+			return nil
+		}
+		listErr, ok := err.(ListError)
+		assert.True(ok)
+		return listErr
+	}
+
+	// nothing recorded yet
+	assert.Empty(list(onTag...))
+	assert.Equal(map[string]string{"v1.0.0": original}, store["example.com/foo/bar"])
+
+	smgr.versions["example.com/foo/bar"] = []gps.PairedVersion{
+		gps.NewVersion("v1.0.0").Pair(gps.Revision(moved)),
+	}
+	movedErr := &TagChange{Tag: "v1.0.0", Kind: TagMoved, Revision: original, Current: moved}
+
+	// reported on every scan
+	for i := 0; i < 2; i++ {
+		errs := list(onTag...)
+		assert.Len(errs, 1)
+		assert.Equal(movedErr, errs[0].Err)
+	}
+
+	// locked commits are checked without a recorded tag
+	errs := list(depmap.Dependency{Name: "example.com/foo/baz", Revision: original, Version: "v1.0.0"})
+	assert.Len(errs, 1)
+	assert.Equal(&TagChange{Tag: "v1.0.0", Kind: TagMoved, Revision: original, Current: "rev-v1.0.0"}, errs[0].Err)
+
+	// a dependency locked to the new commit resolves the change
+	assert.Empty(list(depmap.Dependency{Name: "example.com/foo/bar", Revision: moved, Version: "v1.0.0"}))
+	assert.Empty(list(onTag...))
+
+	smgr.versions["example.com/foo/bar"] = pairedVersions("v1.0.1")
+	errs = list(onTag...)
+	assert.Len(errs, 1)
+	assert.Equal(&TagChange{Tag: "v1.0.0", Kind: TagDeleted, Revision: moved}, errs[0].Err)
+}
@@ -2,13 +2,19 @@ package updater
 
 import (
 	"context"
+	"net/url"
 	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/Masterminds/semver"
 	"github.com/golang/dep/gps"
 	"github.com/pkg/errors"
 
+	"github.com/go-fresh/go-fresh/apidiff"
 	"github.com/go-fresh/go-fresh/depmap"
+	"github.com/go-fresh/go-fresh/vuln"
 )
 
 // Update represents a dependency update to perform.
@@ -17,18 +23,160 @@ type Update struct {
 	Name        string
 	Revision    string
 
+	// Manifest is the directory of the manifest declaring the dependency, and Manager the
+	// name of its dependency manager. Manager is empty for projects registered before it
+	// was recorded.
+	Manifest string
+	Manager  string
+
 	From string
 	To   string
 
-	// CommitsBehind int
-	// TimeBehind    time.Duration
+	// Prerelease is set when To is a prerelease version.
+	Prerelease bool
+
+	// Bump is the update type from From to To, UpdatePatch, UpdateMinor or UpdateMajor.
+	// It is empty when the current version is unknown.
+	Bump string
+
+	// Blocked is set when To does not satisfy the dependency's declared constraint,
+	// so the update requires changing the constraint.
+	Blocked bool
+
+	// Source is the fork, or alternate source, the versions were listed from.
+	Source string
+
+	// Upstream is set when the upstream project of a fork has a newer version than
+	// the fork itself, From is the fork's latest version and To the upstream's.
+	Upstream bool
+
+	// Branch is the branch tracked by the dependency, From and To are then revisions.
+	Branch string
+
+	// FromRevision is the dependency's current revision.
+	FromRevision string
+
+	// CommitsBehind is the number of commits From is behind To and TimeBehind the time between
+	// their commits. FromDate and ToDate are the commit dates of From and To. They are only
+	// set when Options.History is set.
+	CommitsBehind int
+	TimeBehind    time.Duration
+	FromDate      time.Time
+	ToDate        time.Time
+
+	// Migration is set for major updates of Go modules that change the module's import path
+	// to Path. They require rewriting imports rather than changing the required version.
+	Migration bool
+	Path      string
+
+	// Security is set for updates fixing the vulnerabilities listed in Advisories. They take
+	// priority over routine updates and are not subject to update policies.
+	Security   bool
+	Advisories []string
+
+	// ReleaseNotes are the markdown release notes between From and To, for the PR description.
+	ReleaseNotes string
+
+	// FromLicense and ToLicense are the SPDX identifiers of the dependency's license at From
+	// and To. They are only set when Options.Licenses is set.
+	FromLicense string
+	ToLicense   string
+
+	// APIChanges are the breaking changes between From and To to the dependency's exported
+	// identifiers the project refers to. They are only set when Options.API is set.
+	APIChanges []apidiff.Change
+
+	// Retracted is set when the authors of the module retracted From, for the reason in
+	// Retraction. The update moves off the version, To may then be older than From. Like
+	// security updates, retracted updates are not subject to update policies.
+	Retracted  bool
+	Retraction string
+}
+
+// Breaking reports if the update changes API the project refers to.
+func (u Update) Breaking() bool {
+	return len(u.APIChanges) > 0
+}
+
+// LicenseChanged reports if the dependency's license differs between From and To.
+func (u Update) LicenseChanged() bool {
+	return u.FromLicense != "" && u.ToLicense != "" && u.FromLicense != u.ToLicense
+}
+
+// manifest returns the manifest directory of the update, the project root when unset.
+func (u Update) manifest() string {
+	return manifestDir(u.Manifest)
+}
+
+// Options configures how List finds updates.
+type Options struct {
+	// CompareUpstream reports forks whose upstream project has released a newer version.
+	CompareUpstream bool
+
+	// Config is the project's configuration, updates it does not permit are left out.
+	Config *depmap.Config
+
+	// Policy is the update policy for dependencies without a policy in the project's configuration.
+	Policy Policy
+
+	// History measures how stale dependencies are, updates are reported without
+	// staleness when it is nil.
+	History History
+
+	// Licenses detects the dependencies' licenses, updates are reported without licenses
+	// when it is nil.
+	Licenses LicenseDetector
+
+	// API compares the exported API of the dependencies the project refers to, updates are
+	// reported without API changes when it is nil.
+	API APIAnalyzer
+
+	// Tags records the tags of the dependencies' sources, dependencies on a tag that moved or
+	// was deleted since the previous scan are reported with a TagChange error. Tags are not
+	// checked when it is nil.
+	Tags TagStore
+
+	// Retractions reads the versions retracted by the authors of Go modules, retracted versions
+	// are neither proposed nor kept. Retractions are not checked when it is nil.
+	Retractions RetractionSource
+
+	// Advisories are matched against the dependencies to propose security updates.
+	Advisories *vuln.Database
+
+	// Ancestry matches dependencies locked to a commit to advisories anywhere within their commit
+	// ranges, only the commits the advisories name are matched when it is nil.
+	Ancestry vuln.Ancestry
+
+	// Workers is the number of sources queried concurrently, defaults to DefaultWorkers.
+	Workers int
+
+	// Timeout limits each query of a source, zero waits indefinitely.
+	Timeout time.Duration
 }
 
-// List returns all the dependency updates possible on a list of dependencies.
-func List(ctx context.Context, tmpDir string, deps []depmap.Dependency) (map[string][]Update, error) {
-	projects := map[gps.ProjectRoot][]depmap.Dependency{}
-	updates := map[string][]Update{}
+// DefaultWorkers is the number of sources List queries concurrently by default.
+const DefaultWorkers = 8
 
+func (o *Options) workers() int {
+	if o.Workers > 0 {
+		return o.Workers
+	}
+	return DefaultWorkers
+}
+
+// policy resolves the update policy of a dependency, preferring the most specific configuration.
+func (o *Options) policy(dep string) string {
+	if p := o.Config.PolicyFor(dep); p != "" {
+		return p
+	}
+	return string(o.Policy)
+}
+
+// List returns all the dependency updates possible on a list of dependencies, keyed by project root.
+// opts may be nil. Projects are scanned concurrently, when some dependencies fail the updates of the
+// others are returned along with a ListError. When ctx is done the scan stops and the updates found
+// so far are returned with the context's error.
+func List(ctx context.Context, tmpDir string, deps []depmap.Dependency, opts *Options) (map[string][]Update, error) {
 	smgr, err := gps.NewSourceManager(gps.SourceManagerConfig{
 		DisableLocking: false,
 		Cachedir:       tmpDir,
@@ -36,87 +184,668 @@ func List(ctx context.Context, tmpDir string, deps []depmap.Dependency) (map[str
 	if err != nil {
 		return nil, errors.Wrapf(err, "unable to create dep/gps source manager")
 	}
+	defer smgr.Release()
+
+	return listUpdates(ctx, smgr, deps, opts)
+}
+
+// sourceManager is the part of gps.SourceManager List uses.
+type sourceManager interface {
+	DeduceProjectRoot(ip string) (gps.ProjectRoot, error)
+	ListVersions(id gps.ProjectIdentifier) ([]gps.PairedVersion, error)
+	SourceURLsForPath(ip string) ([]*url.URL, error)
+}
+
+func listUpdates(ctx context.Context, smgr sourceManager, deps []depmap.Dependency, opts *Options) (map[string][]Update, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	var (
+		mu       sync.Mutex
+		projects = map[gps.ProjectIdentifier][]depmap.Dependency{}
+		updates  = map[string][]Update{}
+		errs     = ListError{}
+	)
+
+	record := func(depErrs ...*DependencyError) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, depErrs...)
+	}
+	fail := func(err error, deps ...depmap.Dependency) {
+		for _, dep := range deps {
+			record(&DependencyError{Name: dep.Name, Err: err})
+		}
+	}
+
+	parallel(ctx, opts.workers(), len(deps), func(i int) {
+		dep := deps[i]
 
-	for _, dep := range deps {
-		pr, err := smgr.DeduceProjectRoot(dep.Name)
+		var pr gps.ProjectRoot
+		err := withTimeout(ctx, opts.Timeout, func(ctx context.Context) (err error) {
+			pr, err = smgr.DeduceProjectRoot(dep.Name)
+			return err
+		})
 		if err != nil {
-			return nil, errors.Wrapf(err, "unable to deduce project root for %s", dep.Name)
+			fail(errors.Wrapf(err, "unable to deduce project root for %s", dep.Name), dep)
+			return
+		}
+
+		id := gps.ProjectIdentifier{
+			ProjectRoot: pr,
+			Source:      sourceRoot(pr, dep),
 		}
-		projects[pr] = append(projects[pr], dep)
+
+		mu.Lock()
+		projects[id] = append(projects[id], dep)
+		mu.Unlock()
+	})
+
+	ids := make([]gps.ProjectIdentifier, 0, len(projects))
+	for id := range projects {
+		ids = append(ids, id)
 	}
 
-	for project, projectDeps := range projects {
-		// urls, err := smgr.SourceURLsForPath(string(root))
-		// if err != nil {
-		// 	return nil, errors.Wrapf(err, "unable to determine source urls for %s", root)
-		// }
-		raw, err := smgr.ListVersions(gps.ProjectIdentifier{
-			ProjectRoot: project,
+	parallel(ctx, opts.workers(), len(ids), func(i int) {
+		id := ids[i]
+		projectDeps := projects[id]
+
+		var raw []gps.PairedVersion
+		err := withTimeout(ctx, opts.Timeout, func(ctx context.Context) (err error) {
+			raw, err = smgr.ListVersions(id)
+			return err
 		})
 		if err != nil {
-			return nil, errors.Wrapf(err, "unable to list versions for %s", project)
+			fail(errors.Wrapf(err, "unable to list versions for %s", id), projectDeps...)
+			return
 		}
 
-		branches := []string{}
-		vs := make([]semver.Version, 0, len(raw))
-		pairs := map[semver.Version]gps.PairedVersion{}
-		currentVersions := map[depmap.Dependency]string{}
+		if opts.Tags != nil {
+			record(checkTags(opts.Tags, id, projectDeps, raw)...)
+		}
 
-		for _, r := range raw {
-			rs := r.String()
-			v, err := semver.NewVersion(rs)
-			if err == semver.ErrInvalidSemVer {
-				branches = append(branches, rs)
-				continue
-			}
+		defer holdSource(smgr, id, opts)()
+
+		var retractions map[string][]depmap.Retraction
+		if opts.Retractions != nil {
+			var retractErrs []*DependencyError
+			retractions, retractErrs = readRetractions(ctx, smgr, id, projectDeps, raw, opts)
+			record(retractErrs...)
+		}
+
+		projectUpdates, err := versionUpdates(id, projectDeps, raw, retractions, opts)
+		if err != nil {
+			fail(err, projectDeps...)
+			return
+		}
+
+		// only updates the project allows are worth inspecting the history of
+		projectUpdates = Filter(opts.Config, projectUpdates)
+		if opts.History != nil {
+			record(measureStaleness(ctx, smgr, id, projectUpdates, opts)...)
+		}
+		if opts.Licenses != nil {
+			record(detectLicenses(ctx, smgr, id, projectUpdates, opts)...)
+		}
+		if opts.API != nil {
+			record(compareAPI(ctx, smgr, id, projectDeps, projectUpdates, opts)...)
+		}
+
+		if opts.CompareUpstream && id.Source != "" {
+			var upstreamRaw []gps.PairedVersion
+			err := withTimeout(ctx, opts.Timeout, func(ctx context.Context) (err error) {
+				upstreamRaw, err = smgr.ListVersions(gps.ProjectIdentifier{
+					ProjectRoot: id.ProjectRoot,
+				})
+				return err
+			})
 			if err != nil {
-				return nil, errors.Wrapf(err, "unable to parse semver for %s", rs)
+				fail(errors.Wrapf(err, "unable to list upstream versions for %s", id.ProjectRoot), projectDeps...)
+			} else if u, ok := upstreamUpdate(id, raw, upstreamRaw); ok {
+				projectUpdates = append(projectUpdates, u)
 			}
+		}
 
-			pairs[v] = r
-			for _, dep := range projectDeps {
-				if dep.Revision != r.Revision().String() {
-					continue
-				}
+		// the detected licenses may be denied
+		projectUpdates = Filter(opts.Config, projectUpdates)
+		if len(projectUpdates) == 0 {
+			return
+		}
+
+		root := string(id.ProjectRoot)
+		mu.Lock()
+		updates[root] = append(updates[root], projectUpdates...)
+		mu.Unlock()
+	})
+
+	for _, rootUpdates := range updates {
+		Prioritize(rootUpdates)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return updates, errors.Wrapf(err, "listing updates interrupted")
+	}
+	if len(errs) > 0 {
+		sort.Slice(errs, func(i, j int) bool {
+			return errs[i].Name < errs[j].Name
+		})
+		return updates, errs
+	}
+	return updates, nil
+}
+
+// sourceHolder is implemented by inspectors able to share an opened source between calls,
+// like GitHistory.
+type sourceHolder interface {
+	Hold(gitURL string) (release func())
+}
+
+// holdSource keeps the source of the project open while its updates are inspected, so the
+// inspectors sharing it clone it once per List call.
+func holdSource(smgr sourceManager, id gps.ProjectIdentifier, opts *Options) (release func()) {
+	var holders []sourceHolder
+	for _, v := range []interface{}{opts.History, opts.Licenses, opts.API, opts.Retractions, opts.Ancestry} {
+		if h, ok := v.(sourceHolder); ok {
+			holders = append(holders, h)
+		}
+	}
+	if len(holders) == 0 {
+		return func() {}
+	}
+
+	gitURL, err := sourceURL(smgr, id)
+	if err != nil {
+		// reported by the inspections needing it
+		return func() {}
+	}
+
+	releases := make([]func(), 0, len(holders))
+	for _, h := range holders {
+		releases = append(releases, h.Hold(gitURL))
+	}
+	return func() {
+		for _, release := range releases {
+			release()
+		}
+	}
+}
+
+// measureStaleness sets how far each dependency's revision is behind its update. Updates that
+// cannot be measured are left as they are and their errors returned.
+func measureStaleness(ctx context.Context, smgr sourceManager, id gps.ProjectIdentifier, updates []Update, opts *Options) []*DependencyError {
+	var (
+		gitURL string
+		errs   []*DependencyError
+	)
+	for i, u := range updates {
+		if u.FromRevision == "" {
+			// current revision unknown, nothing to compare
+			continue
+		}
 
-				currentVersions[dep] = v.String()
+		if gitURL == "" {
+			url, err := sourceURL(smgr, id)
+			if err != nil {
+				// without a url no update can be measured
+				for _, u := range updates[i:] {
+					if u.FromRevision != "" {
+						errs = append(errs, &DependencyError{Name: u.Name, Err: err})
+					}
+				}
+				return errs
 			}
+			gitURL = url
+		}
+
+		var s Staleness
+		err := withTimeout(ctx, opts.Timeout, func(ctx context.Context) (err error) {
+			s, err = opts.History.Compare(ctx, gitURL, u.Branch, u.FromRevision, u.Revision)
+			return err
+		})
+		if err != nil {
+			errs = append(errs, &DependencyError{
+				Name: u.Name,
+				Err:  errors.Wrapf(err, "unable to compare %s to %s for %s", u.FromRevision, u.Revision, u.Name),
+			})
+			continue
+		}
+		updates[i].CommitsBehind = s.Commits
+		updates[i].TimeBehind = s.ToDate.Sub(s.FromDate)
+		updates[i].FromDate = s.FromDate
+		updates[i].ToDate = s.ToDate
+	}
+	return errs
+}
 
-			if v.Prerelease() != "" {
-				// skip prerelease
+// detectLicenses sets the license of each dependency at its current revision and at its update.
+// Updates whose licenses cannot be detected are left as they are and their errors returned.
+func detectLicenses(ctx context.Context, smgr sourceManager, id gps.ProjectIdentifier, updates []Update, opts *Options) []*DependencyError {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	gitURL, err := sourceURL(smgr, id)
+	if err != nil {
+		errs := make([]*DependencyError, 0, len(updates))
+		for _, u := range updates {
+			errs = append(errs, &DependencyError{Name: u.Name, Err: err})
+		}
+		return errs
+	}
+
+	var errs []*DependencyError
+	for i, u := range updates {
+		revisions := []string{u.Revision}
+		if u.FromRevision != "" {
+			revisions = append(revisions, u.FromRevision)
+		}
+
+		var licenses []string
+		err := withTimeout(ctx, opts.Timeout, func(ctx context.Context) (err error) {
+			licenses, err = opts.Licenses.Licenses(ctx, gitURL, u.Branch, revisions...)
+			return err
+		})
+		if err != nil {
+			errs = append(errs, &DependencyError{
+				Name: u.Name,
+				Err:  errors.Wrapf(err, "unable to detect licenses of %s", u.Name),
+			})
+			continue
+		}
+		updates[i].ToLicense = licenses[0]
+		if len(licenses) > 1 {
+			updates[i].FromLicense = licenses[1]
+		}
+	}
+	return errs
+}
+
+// compareAPI sets the breaking API changes of each update to the identifiers its dependencies refer
+// to. Updates whose API cannot be compared are left as they are and their errors returned.
+func compareAPI(ctx context.Context, smgr sourceManager, id gps.ProjectIdentifier, deps []depmap.Dependency, updates []Update, opts *Options) []*DependencyError {
+	var (
+		gitURL string
+		errs   []*DependencyError
+	)
+	for i, u := range updates {
+		refs := updateReferences(deps, u)
+		if u.FromRevision == "" || len(refs) == 0 {
+			// nothing to compare against, or nothing that could break
+			continue
+		}
+
+		if gitURL == "" {
+			url, err := sourceURL(smgr, id)
+			if err != nil {
+				errs = append(errs, &DependencyError{Name: u.Name, Err: err})
 				continue
 			}
+			gitURL = url
+		}
+
+		var changes []apidiff.Change
+		err := withTimeout(ctx, opts.Timeout, func(ctx context.Context) (err error) {
+			changes, err = ReferencedAPIChanges(ctx, opts.API, gitURL, u.Branch, string(id.ProjectRoot), deps, u, u.Revision)
+			return err
+		})
+		if err != nil {
+			errs = append(errs, &DependencyError{Name: u.Name, Err: err})
+			continue
+		}
+		if len(changes) > 0 {
+			updates[i].APIChanges = changes
+		}
+	}
+	return errs
+}
+
+// ReferencedAPIChanges compares the API of the update's dependency at its current revision to the
+// to revision, and returns the changes to the identifiers deps refer to. root is the project root
+// of the dependency.
+func ReferencedAPIChanges(ctx context.Context, api APIAnalyzer, gitURL, branch, root string, deps []depmap.Dependency, u Update, to string) ([]apidiff.Change, error) {
+	refs := updateReferences(deps, u)
+	if u.FromRevision == "" || len(refs) == 0 {
+		return nil, nil
+	}
 
-			vs = append(vs, v)
+	changes, err := api.CompareAPI(ctx, gitURL, branch, u.FromRevision, to, referencedPackages(root, refs))
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to compare API of %s to %s for %s", u.FromRevision, to, u.Name)
+	}
+	return apidiff.Referenced(changes, refs), nil
+}
+
+// updateReferences returns the identifiers the dependencies updated by u refer to.
+func updateReferences(deps []depmap.Dependency, u Update) []string {
+	refs := []string{}
+	for _, d := range deps {
+		if d.Name == u.Name && (d.Manifest == u.Manifest || u.Manifest == "." && d.Manifest == "") {
+			refs = append(refs, d.References...)
+		}
+	}
+	return refs
+}
+
+// referencedPackages maps the import paths of the packages refs refer to, to their directory in
+// the dependency's repository.
+func referencedPackages(root string, refs []string) map[string]string {
+	pkgs := map[string]string{}
+	for _, ref := range refs {
+		imp := ref[:strings.LastIndex(ref, ".")]
+		if imp != root && !strings.HasPrefix(imp, root+"/") {
+			continue
+		}
+		dir := strings.TrimPrefix(strings.TrimPrefix(imp, root), "/")
+		if dir == "" {
+			dir = "."
 		}
+		pkgs[imp] = dir
+	}
+	return pkgs
+}
+
+// sourceURL returns the git url of the project's source.
+func sourceURL(smgr sourceManager, id gps.ProjectIdentifier) (string, error) {
+	source := id.Source
+	if source == "" {
+		source = string(id.ProjectRoot)
+	}
+	urls, err := smgr.SourceURLsForPath(source)
+	if err == nil && len(urls) == 0 {
+		err = errors.Errorf("no source urls for %s", id)
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to determine source urls for %s", id)
+	}
+	return urls[0].String(), nil
+}
+
+// sourceRoot returns the alternate source of the dependency's project, or an empty string if there is none.
+// Dependency managers such as govendor record the source of each package, so the package's path
+// below the project root is removed.
+func sourceRoot(root gps.ProjectRoot, dep depmap.Dependency) string {
+	source := dep.Source
+	if source == "" || strings.HasPrefix(source, ".") || strings.HasPrefix(source, "/") {
+		// local replacements have no versions to list
+		return ""
+	}
+
+	sub := strings.TrimPrefix(dep.Name, string(root))
+	if sub != "" && sub != dep.Name {
+		source = strings.TrimSuffix(source, sub)
+	}
+	if source == string(root) {
+		return ""
+	}
+	return source
+}
 
-		if len(vs) == 0 {
-			// no versions, skip project
+// latestVersion returns the newest non-prerelease semver version.
+func latestVersion(raw []gps.PairedVersion) (semver.Version, gps.PairedVersion, bool) {
+	var (
+		latest     semver.Version
+		latestPair gps.PairedVersion
+	)
+	for _, r := range raw {
+		v, err := semver.NewVersion(r.String())
+		if err != nil || v.Prerelease() != "" {
 			continue
 		}
+		if latestPair == nil || v.GreaterThan(latest) {
+			latest, latestPair = v, r
+		}
+	}
+	return latest, latestPair, latestPair != nil
+}
+
+// upstreamUpdate reports if the upstream project has a newer version than the fork.
+func upstreamUpdate(id gps.ProjectIdentifier, forkRaw, upstreamRaw []gps.PairedVersion) (Update, bool) {
+	upstream, upstreamPair, ok := latestVersion(upstreamRaw)
+	if !ok {
+		return Update{}, false
+	}
+
+	u := Update{
+		Name:        string(id.ProjectRoot),
+		ProjectRoot: string(id.ProjectRoot),
+		Revision:    upstreamPair.Revision().String(),
+		Source:      id.Source,
+		Upstream:    true,
+
+		To: upstream.String(),
+	}
+
+	fork, _, ok := latestVersion(forkRaw)
+	if ok {
+		if !upstream.GreaterThan(fork) {
+			return Update{}, false
+		}
+		u.From = fork.String()
+	}
+
+	return u, true
+}
+
+// onVersion reports if the dependency is locked to the version, either by revision or by tag.
+func onVersion(dep depmap.Dependency, pv gps.PairedVersion) bool {
+	return dep.Revision == pv.Revision().String() || dep.Revision == pv.String()
+}
+
+// dependencyPrereleases returns the prerelease versions the dependency opted in to.
+func dependencyPrereleases(conf *depmap.Config, dep string, prereleases []semver.Version) []semver.Version {
+	optedIn := []semver.Version{}
+	for _, v := range prereleases {
+		if conf.AllowsPrerelease(dep, v.Prerelease()) {
+			optedIn = append(optedIn, v)
+		}
+	}
+	return optedIn
+}
 
-		sorted := semver.Collection(vs)
-		sort.Sort(sorted)
+// versionUpdates determines the updates for the dependencies of a single project from its available versions.
+// retractions are the retracted versions of module dependencies, keyed by dependency name.
+func versionUpdates(id gps.ProjectIdentifier, projectDeps []depmap.Dependency, raw []gps.PairedVersion, retractions map[string][]depmap.Retraction, opts *Options) ([]Update, error) {
+	vs := make([]semver.Version, 0, len(raw))
+	prereleases := []semver.Version{}
+	pairs := map[semver.Version]gps.PairedVersion{}
+	currentVersions := make([]*semver.Version, len(projectDeps))
 
-		latest := sorted[len(sorted)-1]
-		latestPair := pairs[latest]
+	for _, r := range raw {
+		rs := r.String()
+		if r.Type() == gps.IsBranch {
+			// branches are only proposed to dependencies tracking them
+			continue
+		}
+		v, err := semver.NewVersion(rs)
+		if err == semver.ErrInvalidSemVer {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse semver for %s", rs)
+		}
 
-		for _, dep := range projectDeps {
-			if dep.Revision == latestPair.Revision().String() {
-				// already on latest
+		pairs[v] = r
+		for i, dep := range projectDeps {
+			if !onVersion(dep, r) {
 				continue
 			}
 
-			updates[string(project)] = append(updates[string(project)], Update{
+			current := v
+			currentVersions[i] = &current
+		}
+
+		if v.Prerelease() != "" {
+			// only proposed to dependencies that opt in
+			prereleases = append(prereleases, v)
+			continue
+		}
+
+		vs = append(vs, v)
+	}
+
+	tagged := len(vs) > 0
+
+	sorted := semver.Collection(vs)
+	sort.Sort(sorted)
+
+	updates := []Update{}
+
+	for i, dep := range projectDeps {
+		branch, tracking, err := trackedBranch(opts.Config, dep, tagged, raw)
+		if err != nil {
+			return nil, err
+		}
+		if tracking {
+			if u, ok := branchUpdate(id, dep, branch, raw); ok {
+				updates = append(updates, u)
+			}
+			continue
+		}
+
+		if !tagged {
+			// no versions to propose
+			continue
+		}
+
+		current := currentVersions[i]
+		start := len(updates)
+
+		// Go modules only update within their import path, higher major versions are migrations
+		depSorted := semver.Collection(sorted)
+		mod, isModule := parseModulePath(dep)
+		var migration *semver.Version
+		if isModule {
+			depSorted, migration = mod.split(sorted)
+			depSorted = withoutRetracted(retractions[dep.Name], depSorted)
+		}
+
+		newUpdate := func(to semver.Version, blocked bool) Update {
+			u := Update{
 				Name:        dep.Name,
-				ProjectRoot: string(project),
-				Revision:    latestPair.Revision().String(),
+				ProjectRoot: string(id.ProjectRoot),
+				Revision:    pairs[to].Revision().String(),
+				Manifest:    dep.Manifest,
+				Manager:     dep.Manager,
+				Source:      id.Source,
 
-				From: currentVersions[dep],
-				To:   latest.String(),
-			})
+				To:         to.String(),
+				Blocked:    blocked,
+				Prerelease: to.Prerelease() != "",
+			}
+			if isModule {
+				u.To = mod.version(to)
+			}
+			if current != nil {
+				u.From = current.String()
+				u.FromRevision = pairs[*current].Revision().String()
+				u.Bump = UpdateType(u.From, u.To)
+			} else {
+				// locked to a revision that is not a release
+				u.FromRevision = dep.Revision
+			}
+			return u
+		}
+
+		isNewer := func(v semver.Version) bool {
+			if onVersion(dep, pairs[v]) {
+				// already on version
+				return false
+			}
+			return current == nil || v.GreaterThan(*current)
+		}
+
+		policy, err := ParsePolicy(opts.policy(dep.Name))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid policy for %s", dep.Name)
+		}
+
+		candidates := depSorted
+		if optedIn := dependencyPrereleases(opts.Config, dep.Name, prereleases); len(optedIn) > 0 {
+			if isModule {
+				optedIn, _ = mod.split(optedIn)
+				optedIn = withoutRetracted(retractions[dep.Name], optedIn)
+			}
+			candidates = append(append(semver.Collection{}, depSorted...), optedIn...)
+			sort.Sort(candidates)
+		}
+
+		var constraint semver.Constraint
+		if dep.Constraint != "" {
+			constraint, err = semver.NewConstraint(dep.Constraint)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to parse constraint %q for %s", dep.Constraint, dep.Name)
+			}
+
+			// only versions within the declared constraint
+			all := candidates
+			candidates = make(semver.Collection, 0, len(all))
+			for _, v := range all {
+				if constraint.Matches(v) == nil {
+					candidates = append(candidates, v)
+				}
+			}
+		}
+
+		for _, v := range policy.candidates(candidates, current, isNewer) {
+			updates = append(updates, newUpdate(v, false))
+		}
+
+		if len(depSorted) > 0 {
+			latest := depSorted[len(depSorted)-1]
+			if constraint != nil && constraint.Matches(latest) != nil && isNewer(latest) {
+				blocked := newUpdate(latest, true)
+				if policy.allows(blocked.Bump) {
+					updates = append(updates, blocked)
+				}
+			}
+		}
+
+		if migration != nil && !mod.incompatible && policy.allows(UpdateMajor) && !RequiresNewerMajor(projectDeps, dep) {
+			u := newUpdate(*migration, false)
+			u.Migration = true
+			u.Path = mod.path(migration.Major())
+			u.Bump = UpdateMajor
+			updates = append(updates, u)
+		}
+
+		if fix, ids, ok := securityFix(opts.Advisories, opts.Ancestry, dep, current, depSorted, isNewer); ok {
+			u := newUpdate(fix, constraint != nil && constraint.Matches(fix) != nil)
+			u.Security, u.Advisories = true, ids
+
+			marked := false
+			for j := start; j < len(updates); j++ {
+				if updates[j].To == u.To && !updates[j].Migration {
+					updates[j].Security, updates[j].Advisories = true, ids
+					marked = true
+				}
+			}
+			if !marked {
+				updates = append(updates, u)
+			}
+		}
+
+		if current == nil {
+			continue
+		}
+		if r, ok := retraction(retractions[dep.Name], *current); ok {
+			marked := false
+			for j := start; j < len(updates); j++ {
+				if !updates[j].Migration {
+					updates[j].Retracted, updates[j].Retraction = true, r.Rationale
+					marked = true
+				}
+			}
+			if !marked && len(depSorted) > 0 {
+				// move off the version even when nothing newer was released
+				latest := depSorted[len(depSorted)-1]
+				u := newUpdate(latest, constraint != nil && constraint.Matches(latest) != nil)
+				u.Retracted, u.Retraction = true, r.Rationale
+				updates = append(updates, u)
+			}
 		}
 	}
 
+	Prioritize(updates)
 	return updates, nil
 }
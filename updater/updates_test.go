@@ -0,0 +1,314 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/dep/gps"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fresh/go-fresh/apidiff"
+	"github.com/go-fresh/go-fresh/depmap"
+)
+
+func pairedVersions(versions ...string) []gps.PairedVersion {
+	pvs := make([]gps.PairedVersion, 0, len(versions))
+	for _, v := range versions {
+		pvs = append(pvs, gps.NewVersion(v).Pair(gps.Revision("rev-"+v)))
+	}
+	return pvs
+}
+
+func TestVersionUpdates(t *testing.T) {
+	raw := pairedVersions("v1.0.0", "v1.1.0", "v1.2.0", "v1.3.0-rc1", "v2.0.0", "master")
+
+	for i, c := range []struct {
+		expected []Update
+		dep      depmap.Dependency
+	}{
+		{
+			[]Update{
+				{Name: "example.com/foo", ProjectRoot: "example.com/foo", Revision: "rev-v1.2.0", From: "1.0.0", FromRevision: "rev-v1.0.0", To: "1.2.0", Bump: UpdateMinor},
+				{Name: "example.com/foo", ProjectRoot: "example.com/foo", Revision: "rev-v2.0.0", From: "1.0.0", FromRevision: "rev-v1.0.0", To: "2.0.0", Bump: UpdateMajor},
+			},
+			depmap.Dependency{Name: "example.com/foo", Revision: "rev-v1.0.0"},
+		},
+		{
+			[]Update{
+				{Name: "example.com/foo", ProjectRoot: "example.com/foo", Revision: "rev-v1.2.0", From: "1.0.0", FromRevision: "rev-v1.0.0", To: "1.2.0", Bump: UpdateMinor},
+				{Name: "example.com/foo", ProjectRoot: "example.com/foo", Revision: "rev-v2.0.0", From: "1.0.0", FromRevision: "rev-v1.0.0", To: "2.0.0", Bump: UpdateMajor, Blocked: true},
+			},
+			depmap.Dependency{Name: "example.com/foo", Revision: "rev-v1.0.0", Constraint: ">= 1.0.0, < 2.0.0"},
+		},
+		{
+			[]Update{
+				{Name: "example.com/foo", ProjectRoot: "example.com/foo", Revision: "rev-v2.0.0", From: "1.2.0", FromRevision: "rev-v1.2.0", To: "2.0.0", Bump: UpdateMajor, Blocked: true},
+			},
+			depmap.Dependency{Name: "example.com/foo", Revision: "rev-v1.2.0", Constraint: ">= 1.0.0, < 2.0.0"},
+		},
+		{
+			[]Update{},
+			depmap.Dependency{Name: "example.com/foo", Revision: "rev-v2.0.0"},
+		},
+		{
+			[]Update{},
+			depmap.Dependency{Name: "example.com/foo", Revision: "v2.0.0", Version: "v2.0.0"},
+		},
+		{
+			[]Update{
+				{Name: "example.com/foo", ProjectRoot: "example.com/foo", Revision: "rev-v2.0.0", FromRevision: "abcdef", To: "2.0.0"},
+			},
+			depmap.Dependency{Name: "example.com/foo", Revision: "abcdef"},
+		},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert := require.New(t)
+
+			actual, err := versionUpdates(gps.ProjectIdentifier{ProjectRoot: "example.com/foo"}, []depmap.Dependency{c.dep}, raw, nil, &Options{})
+			assert.NoError(err)
+			assert.Equal(c.expected, actual)
+		})
+	}
+}
+
+func TestVersionUpdates_Prereleases(t *testing.T) {
+	raw := pairedVersions("v1.0.0", "v1.1.0", "v1.2.0-alpha", "v1.2.0-rc1", "master")
+	opts := &Options{Config: &depmap.Config{
+		Prereleases: map[string][]string{"example.com/foo": {"rc"}},
+	}}
+
+	for i, c := range []struct {
+		expected []Update
+		dep      depmap.Dependency
+	}{
+		{
+			[]Update{
+				{Name: "example.com/foo", ProjectRoot: "example.com/foo", Revision: "rev-v1.2.0-rc1", From: "1.0.0", FromRevision: "rev-v1.0.0", To: "1.2.0-rc1", Bump: UpdateMinor, Prerelease: true},
+			},
+			depmap.Dependency{Name: "example.com/foo", Revision: "rev-v1.0.0"},
+		},
+		{
+			[]Update{
+				{Name: "example.com/foo/bar", ProjectRoot: "example.com/foo", Revision: "rev-v1.2.0-rc1", From: "1.1.0", FromRevision: "rev-v1.1.0", To: "1.2.0-rc1", Bump: UpdateMinor, Prerelease: true},
+			},
+			depmap.Dependency{Name: "example.com/foo/bar", Revision: "rev-v1.1.0"},
+		},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert := require.New(t)
+
+			actual, err := versionUpdates(gps.ProjectIdentifier{ProjectRoot: "example.com/foo"}, []depmap.Dependency{c.dep}, raw, nil, opts)
+			assert.NoError(err)
+			assert.Equal(c.expected, actual)
+		})
+	}
+
+	// without opting in prereleases are never proposed
+	actual, err := versionUpdates(gps.ProjectIdentifier{ProjectRoot: "example.com/foo"}, []depmap.Dependency{{Name: "example.com/foo", Revision: "rev-v1.0.0"}}, raw, nil, &Options{})
+	require.NoError(t, err)
+	require.Equal(t, []Update{
+		{Name: "example.com/foo", ProjectRoot: "example.com/foo", Revision: "rev-v1.1.0", From: "1.0.0", FromRevision: "rev-v1.0.0", To: "1.1.0", Bump: UpdateMinor},
+	}, actual)
+}
+
+func TestSourceRoot(t *testing.T) {
+	for i, c := range []struct {
+		expected string
+		dep      depmap.Dependency
+	}{
+		{"", depmap.Dependency{Name: "github.com/foo/bar"}},
+		{"github.com/fork/bar", depmap.Dependency{Name: "github.com/foo/bar", Source: "github.com/fork/bar"}},
+		{"github.com/fork/bar", depmap.Dependency{Name: "github.com/foo/bar/baz", Source: "github.com/fork/bar/baz"}},
+		{"https://git.example.com/bar.git", depmap.Dependency{Name: "github.com/foo/bar/baz", Source: "https://git.example.com/bar.git"}},
+		{"", depmap.Dependency{Name: "github.com/foo/bar/baz", Source: "github.com/foo/bar/baz"}},
+		{"", depmap.Dependency{Name: "github.com/foo/bar", Source: "../bar"}},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			assert := require.New(t)
+
+			assert.Equal(c.expected, sourceRoot("github.com/foo/bar", c.dep))
+		})
+	}
+}
+
+func TestUpstreamUpdate(t *testing.T) {
+	assert := require.New(t)
+
+	id := gps.ProjectIdentifier{ProjectRoot: "example.com/foo", Source: "example.com/fork"}
+
+	u, ok := upstreamUpdate(id, pairedVersions("v1.0.0", "v1.1.0"), pairedVersions("v1.0.0", "v1.1.0", "v1.2.0", "v1.3.0-rc1"))
+	assert.True(ok)
+	assert.Equal(Update{
+		Name:        "example.com/foo",
+		ProjectRoot: "example.com/foo",
+		Revision:    "rev-v1.2.0",
+		Source:      "example.com/fork",
+		Upstream:    true,
+		From:        "1.1.0",
+		To:          "1.2.0",
+	}, u)
+
+	_, ok = upstreamUpdate(id, pairedVersions("v1.0.0", "v1.2.0"), pairedVersions("v1.0.0", "v1.2.0"))
+	assert.False(ok)
+}
+
+type fakeSourceManager struct {
+	versions map[gps.ProjectRoot][]gps.PairedVersion
+	delay    map[gps.ProjectRoot]time.Duration
+}
+
+func (sm *fakeSourceManager) DeduceProjectRoot(ip string) (gps.ProjectRoot, error) {
+	parts := strings.Split(ip, "/")
+	if len(parts) < 3 {
+		return "", fmt.Errorf("unable to deduce %s", ip)
+	}
+	return gps.ProjectRoot(strings.Join(parts[:3], "/")), nil
+}
+
+func (sm *fakeSourceManager) ListVersions(id gps.ProjectIdentifier) ([]gps.PairedVersion, error) {
+	time.Sleep(sm.delay[id.ProjectRoot])
+	raw, ok := sm.versions[id.ProjectRoot]
+	if !ok {
+		return nil, fmt.Errorf("no such source %s", id.ProjectRoot)
+	}
+	return raw, nil
+}
+
+func (sm *fakeSourceManager) SourceURLsForPath(ip string) ([]*url.URL, error) {
+	return []*url.URL{{Scheme: "https", Host: ip}}, nil
+}
+
+func TestListUpdates(t *testing.T) {
+	assert := require.New(t)
+
+	smgr := &fakeSourceManager{
+		versions: map[gps.ProjectRoot][]gps.PairedVersion{
+			"example.com/foo/bar":  pairedVersions("v1.0.0", "v1.1.0"),
+			"example.com/foo/baz":  pairedVersions("v1.0.0"),
+			"example.com/foo/slow": pairedVersions("v1.0.0", "v2.0.0"),
+		},
+		delay: map[gps.ProjectRoot]time.Duration{
+			"example.com/foo/slow": time.Second,
+		},
+	}
+	deps := []depmap.Dependency{
+		{Name: "example.com/foo/bar/pkg", Revision: "rev-v1.0.0"},
+		{Name: "example.com/foo/baz", Revision: "rev-v1.0.0"},
+		{Name: "example.com/foo/missing", Revision: "rev-v1.0.0"},
+		{Name: "example.com/foo/slow", Revision: "rev-v1.0.0"},
+		{Name: "invalid", Revision: "rev-v1.0.0"},
+	}
+
+	updates, err := listUpdates(context.Background(), smgr, deps, &Options{Workers: 2, Timeout: 50 * time.Millisecond})
+	assert.Equal(map[string][]Update{
+		"example.com/foo/bar": {
+			{Name: "example.com/foo/bar/pkg", ProjectRoot: "example.com/foo/bar", Revision: "rev-v1.1.0", From: "1.0.0", FromRevision: "rev-v1.0.0", To: "1.1.0", Bump: UpdateMinor},
+		},
+	}, updates)
+
+	listErr, ok := err.(ListError)
+	assert.True(ok)
+	failed := []string{}
+	for _, e := range listErr {
+		failed = append(failed, e.Name)
+	}
+	assert.Equal([]string{"example.com/foo/missing", "example.com/foo/slow", "invalid"}, failed)
+}
+
+func TestListUpdates_Cancelled(t *testing.T) {
+	assert := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	smgr := &fakeSourceManager{
+		versions: map[gps.ProjectRoot][]gps.PairedVersion{
+			"example.com/foo/bar": pairedVersions("v1.0.0", "v1.1.0"),
+		},
+	}
+	updates, err := listUpdates(ctx, smgr, []depmap.Dependency{{Name: "example.com/foo/bar", Revision: "rev-v1.0.0"}}, nil)
+	assert.Error(err)
+	assert.Equal(context.Canceled, errors.Cause(err))
+	assert.Empty(updates)
+}
+
+type fakeAPIAnalyzer struct {
+	pkgs map[string]string
+}
+
+func (a *fakeAPIAnalyzer) CompareAPI(ctx context.Context, gitURL, branch, from, to string, pkgs map[string]string) ([]apidiff.Change, error) {
+	a.pkgs = pkgs
+	return []apidiff.Change{
+		{Package: "example.com/foo/bar/pkg", Name: "Client.Do", Kind: apidiff.Removed},
+		{Package: "example.com/foo/bar/pkg", Name: "Unused", Kind: apidiff.Removed},
+	}, nil
+}
+
+func TestListUpdates_API(t *testing.T) {
+	assert := require.New(t)
+
+	smgr := &fakeSourceManager{
+		versions: map[gps.ProjectRoot][]gps.PairedVersion{
+			"example.com/foo/bar": pairedVersions("v1.0.0", "v1.1.0"),
+		},
+	}
+	deps := []depmap.Dependency{
+		{
+			Name:       "example.com/foo/bar/pkg",
+			Revision:   "rev-v1.0.0",
+			References: []string{"example.com/foo/bar/pkg.Client", "example.com/foo/bar/pkg.New"},
+		},
+	}
+
+	api := &fakeAPIAnalyzer{}
+	updates, err := listUpdates(context.Background(), smgr, deps, &Options{API: api})
+	assert.NoError(err)
+	assert.Equal(map[string]string{"example.com/foo/bar/pkg": "pkg"}, api.pkgs)
+
+	u := updates["example.com/foo/bar"][0]
+	assert.True(u.Breaking())
+	assert.Equal([]apidiff.Change{
+		{Package: "example.com/foo/bar/pkg", Name: "Client.Do", Kind: apidiff.Removed},
+	}, u.APIChanges)
+}
+
+type fakeHistory struct {
+	compared []string
+}
+
+func (h *fakeHistory) Compare(ctx context.Context, gitURL, branch, from, to string) (Staleness, error) {
+	h.compared = append(h.compared, gitURL)
+	return Staleness{Commits: 3}, nil
+}
+
+func TestListUpdates_Filtered(t *testing.T) {
+	assert := require.New(t)
+
+	smgr := &fakeSourceManager{
+		versions: map[gps.ProjectRoot][]gps.PairedVersion{
+			"example.com/foo/bar": pairedVersions("v1.0.0", "v1.1.0"),
+			"example.com/foo/baz": pairedVersions("v1.0.0", "v1.1.0"),
+		},
+	}
+	deps := []depmap.Dependency{
+		{Name: "example.com/foo/bar", Revision: "rev-v1.0.0"},
+		{Name: "example.com/foo/baz", Revision: "rev-v1.0.0"},
+	}
+
+	// ignored dependencies are not compared
+	history := &fakeHistory{}
+	updates, err := listUpdates(context.Background(), smgr, deps, &Options{
+		Config:  &depmap.Config{Ignore: []string{"example.com/foo/baz"}},
+		History: history,
+	})
+	assert.NoError(err)
+	assert.Len(history.compared, 1)
+	assert.Contains(history.compared[0], "bar")
+	assert.Len(updates, 1)
+	assert.Equal(3, updates["example.com/foo/bar"][0].CommitsBehind)
+}
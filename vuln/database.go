@@ -0,0 +1,225 @@
+package vuln
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+
+	"github.com/go-fresh/go-fresh/depmap"
+)
+
+// Database is a set of advisories loaded into memory.
+type Database struct {
+	Advisories []*Advisory
+}
+
+// Finding is an advisory affecting a dependency.
+type Finding struct {
+	Advisory *Advisory
+
+	// Fixed is the lowest version fixing the vulnerability, empty if there is no fix. For
+	// revisions, whose version is unknown, it is the highest fixed version, fixing every
+	// affected version.
+	Fixed string
+}
+
+// Load reads the advisories of a directory, or a .zip, .tar.gz or .tgz archive, of OSV JSON
+// files. Index files of the Go vulnerability database, in an "index" directory, are skipped.
+func Load(name string) (*Database, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open advisory database %s", name)
+	}
+
+	db := &Database{}
+	switch {
+	case info.IsDir():
+		err = db.loadDir(name)
+	case strings.HasSuffix(name, ".zip"):
+		err = db.loadZip(name)
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		err = db.loadTar(name)
+	default:
+		err = errors.Errorf("unsupported advisory database format %s", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(db.Advisories, func(i, j int) bool {
+		return db.Advisories[i].ID < db.Advisories[j].ID
+	})
+	return db, nil
+}
+
+// isAdvisoryFile reports if the slash separated path within a database holds an advisory.
+func isAdvisoryFile(name string) bool {
+	if path.Ext(name) != ".json" {
+		return false
+	}
+	for _, dir := range strings.Split(path.Dir(name), "/") {
+		if dir == "index" {
+			return false
+		}
+	}
+	return true
+}
+
+func (db *Database) add(name string, r io.Reader) error {
+	a := &Advisory{}
+	err := json.NewDecoder(r).Decode(a)
+	if err != nil {
+		return errors.Wrapf(err, "unable to parse advisory %s", name)
+	}
+	if a.ID == "" {
+		return errors.Errorf("advisory %s has no id", name)
+	}
+	db.Advisories = append(db.Advisories, a)
+	return nil
+}
+
+func (db *Database) loadDir(dir string) error {
+	return filepath.Walk(dir, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !isAdvisoryFile(filepath.ToSlash(rel)) {
+			return nil
+		}
+
+		f, err := os.Open(name)
+		if err != nil {
+			return errors.Wrapf(err, "unable to open advisory %s", name)
+		}
+		defer f.Close()
+
+		return db.add(rel, f)
+	})
+}
+
+func (db *Database) loadZip(name string) error {
+	r, err := zip.OpenReader(name)
+	if err != nil {
+		return errors.Wrapf(err, "unable to open advisory archive %s", name)
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() || !isAdvisoryFile(f.Name) {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return errors.Wrapf(err, "unable to open advisory %s", f.Name)
+		}
+		err = db.add(f.Name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *Database) loadTar(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return errors.Wrapf(err, "unable to open advisory archive %s", name)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return errors.Wrapf(err, "unable to decompress advisory archive %s", name)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "unable to read advisory archive %s", name)
+		}
+		if hdr.Typeflag != tar.TypeReg || !isAdvisoryFile(hdr.Name) {
+			continue
+		}
+
+		err = db.add(hdr.Name, tr)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Ancestry resolves the commit history of repositories, to match revisions within GIT ranges.
+type Ancestry interface {
+	// IsAncestor reports if the ancestor commit is reachable from the descendant revision in
+	// the repository at repo. A commit is its own ancestor.
+	IsAncestor(repo, ancestor, descendant string) (bool, error)
+}
+
+// Affecting returns the advisories affecting the dependency at its version, or its revision
+// when it has no semver version. Revisions only match the commits GIT ranges name. db may be nil.
+func (db *Database) Affecting(dep depmap.Dependency) []Finding {
+	return db.AffectingWith(dep, nil)
+}
+
+// AffectingWith is Affecting, matching revisions anywhere within GIT ranges by their ancestry.
+// ancestry may be nil.
+func (db *Database) AffectingWith(dep depmap.Dependency, ancestry Ancestry) []Finding {
+	if db == nil {
+		return nil
+	}
+
+	version, err := semver.NewVersion(dep.Version)
+	if err != nil && strings.Contains(dep.Revision, ".") {
+		// some managers record tags as revisions, commit hashes have no dots
+		version, err = semver.NewVersion(dep.Revision)
+	}
+	hasVersion := err == nil
+
+	findings := []Finding{}
+	for _, a := range db.Advisories {
+		if a.Withdrawn != nil {
+			continue
+		}
+
+		for _, affected := range a.Affected {
+			if !affected.matchesPackage(dep.Name) {
+				continue
+			}
+
+			if hasVersion {
+				if ok, fixed := affected.affectsVersion(version); ok {
+					findings = append(findings, Finding{Advisory: a, Fixed: fixed})
+					break
+				}
+				continue
+			}
+
+			if dep.Revision != "" && affected.affectsRevision(dep.Revision, ancestry) {
+				findings = append(findings, Finding{Advisory: a, Fixed: affected.latestFixed()})
+				break
+			}
+		}
+	}
+	return findings
+}
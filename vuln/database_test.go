@@ -0,0 +1,187 @@
+package vuln
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fresh/go-fresh/depmap"
+)
+
+const fixtureDir = "testdata/advisories"
+
+// archiveFixture packs the fixture advisories into an archive written by add.
+func archiveFixture(t *testing.T, add func(name string, r io.Reader, size int64) error) {
+	err := filepath.Walk(fixtureDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(fixtureDir, p)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return add(filepath.ToSlash(rel), f, info.Size())
+	})
+	require.NoError(t, err)
+}
+
+func TestLoad(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	zipPath := filepath.Join(tmp, "advisories.zip")
+	{
+		f, err := os.Create(zipPath)
+		require.NoError(t, err)
+		w := zip.NewWriter(f)
+		archiveFixture(t, func(name string, r io.Reader, size int64) error {
+			fw, err := w.Create(name)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(fw, r)
+			return err
+		})
+		require.NoError(t, w.Close())
+		require.NoError(t, f.Close())
+	}
+
+	tarPath := filepath.Join(tmp, "advisories.tar.gz")
+	{
+		f, err := os.Create(tarPath)
+		require.NoError(t, err)
+		gz := gzip.NewWriter(f)
+		w := tar.NewWriter(gz)
+		archiveFixture(t, func(name string, r io.Reader, size int64) error {
+			err := w.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: size, Typeflag: tar.TypeReg})
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(w, r)
+			return err
+		})
+		require.NoError(t, w.Close())
+		require.NoError(t, gz.Close())
+		require.NoError(t, f.Close())
+	}
+
+	for _, name := range []string{fixtureDir, zipPath, tarPath} {
+		t.Run(filepath.Base(name), func(t *testing.T) {
+			assert := require.New(t)
+
+			db, err := Load(name)
+			assert.NoError(err)
+
+			ids := []string{}
+			for _, a := range db.Advisories {
+				ids = append(ids, a.ID)
+			}
+			assert.Equal([]string{"GO-2018-0001", "GO-2018-0002", "GO-2018-0003"}, ids)
+		})
+	}
+
+	_, err = Load(filepath.Join(tmp, "missing"))
+	require.Error(t, err)
+}
+
+func TestAffecting(t *testing.T) {
+	db, err := Load(fixtureDir)
+	require.NoError(t, err)
+
+	for i, c := range []struct {
+		expected map[string]string
+		dep      depmap.Dependency
+	}{
+		{map[string]string{"GO-2018-0001": "1.2.1"}, depmap.Dependency{Name: "github.com/foo/bar", Version: "v1.2.0"}},
+		{map[string]string{"GO-2018-0001": "1.2.1"}, depmap.Dependency{Name: "github.com/Foo/Bar/pkg", Version: "v0.1.0"}},
+		{map[string]string{}, depmap.Dependency{Name: "github.com/foo/bar", Version: "v1.2.1"}},
+		{map[string]string{"GO-2018-0001": "1.3.2"}, depmap.Dependency{Name: "github.com/foo/bar", Version: "v1.3.1"}},
+		{map[string]string{}, depmap.Dependency{Name: "github.com/foo/bar", Version: "v1.3.2"}},
+		{map[string]string{"GO-2018-0001": "1.2.1"}, depmap.Dependency{Name: "github.com/foo/bar", Revision: "v1.0.0"}},
+		{map[string]string{}, depmap.Dependency{Name: "github.com/foo/barbaz", Version: "v1.2.0"}},
+		{map[string]string{"GO-2018-0002": ""}, depmap.Dependency{Name: "github.com/acme/parser", Version: "v2.1.0"}},
+		{map[string]string{}, depmap.Dependency{Name: "github.com/acme/parser", Version: "v2.1.1"}},
+		{map[string]string{"GO-2018-0002": ""}, depmap.Dependency{Name: "github.com/acme/parser", Revision: "1111111111111111111111111111111111111111"}},
+		{map[string]string{}, depmap.Dependency{Name: "github.com/acme/parser", Revision: "3333333333333333333333333333333333333333"}},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			actual := map[string]string{}
+			for _, f := range db.Affecting(c.dep) {
+				actual[f.Advisory.ID] = f.Fixed
+			}
+			require.Equal(t, c.expected, actual)
+		})
+	}
+
+	var none *Database
+	require.Empty(t, none.Affecting(depmap.Dependency{Name: "github.com/foo/bar", Version: "v1.2.0"}))
+}
+
+// linearHistory is a repository whose commits each descend from the previous one.
+type linearHistory []string
+
+func (h linearHistory) IsAncestor(repo, ancestor, descendant string) (bool, error) {
+	a, d := -1, -1
+	for i, c := range h {
+		if c == ancestor {
+			a = i
+		}
+		if c == descendant {
+			d = i
+		}
+	}
+	if a < 0 || d < 0 {
+		return false, fmt.Errorf("unknown commit in %s", repo)
+	}
+	return a <= d, nil
+}
+
+func TestAffectingWith(t *testing.T) {
+	db, err := Load(fixtureDir)
+	require.NoError(t, err)
+
+	history := linearHistory{
+		"0000000000000000000000000000000000000000",
+		"1111111111111111111111111111111111111111",
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		"2222222222222222222222222222222222222222",
+		"3333333333333333333333333333333333333333",
+	}
+
+	for i, c := range []struct {
+		expected []string
+		revision string
+	}{
+		{[]string{}, "0000000000000000000000000000000000000000"},
+		{[]string{"GO-2018-0002"}, "1111111111111111111111111111111111111111"},
+		{[]string{"GO-2018-0002"}, "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"},
+		{[]string{}, "2222222222222222222222222222222222222222"},
+		{[]string{}, "3333333333333333333333333333333333333333"},
+		{[]string{}, "ffffffffffffffffffffffffffffffffffffffff"},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			actual := []string{}
+			for _, f := range db.AffectingWith(depmap.Dependency{Name: "github.com/acme/parser", Revision: c.revision}, history) {
+				actual = append(actual, f.Advisory.ID)
+			}
+			require.Equal(t, c.expected, actual)
+		})
+	}
+
+	// without ancestry only the named commits match
+	require.Empty(t, db.Affecting(depmap.Dependency{Name: "github.com/acme/parser", Revision: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"}))
+}
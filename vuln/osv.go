@@ -0,0 +1,235 @@
+// Package vuln matches dependencies against an offline database of OSV advisories.
+package vuln
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+)
+
+// Advisory is a vulnerability report in the OSV format, see https://ossf.github.io/osv-schema/.
+type Advisory struct {
+	ID         string      `json:"id"`
+	Summary    string      `json:"summary"`
+	Details    string      `json:"details"`
+	Aliases    []string    `json:"aliases"`
+	Modified   time.Time   `json:"modified"`
+	Withdrawn  *time.Time  `json:"withdrawn,omitempty"`
+	Affected   []Affected  `json:"affected"`
+	References []Reference `json:"references"`
+}
+
+// Affected describes the versions of a package an advisory applies to.
+type Affected struct {
+	Package  Package  `json:"package"`
+	Ranges   []Range  `json:"ranges"`
+	Versions []string `json:"versions"`
+}
+
+// Package identifies an affected package.
+type Package struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+}
+
+// Range types.
+const (
+	RangeSemver    = "SEMVER"
+	RangeEcosystem = "ECOSYSTEM"
+	RangeGit       = "GIT"
+)
+
+// Range is a list of events introducing and fixing a vulnerability.
+type Range struct {
+	Type   string  `json:"type"`
+	Repo   string  `json:"repo,omitempty"`
+	Events []Event `json:"events"`
+}
+
+// Event is a single version, or commit for GIT ranges, where a vulnerability changes.
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+}
+
+// Reference links to more information about an advisory.
+type Reference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// ecosystemGo is the OSV ecosystem of Go modules.
+const ecosystemGo = "Go"
+
+// matchesPackage reports if the dependency's package belongs to the affected module.
+func (a Affected) matchesPackage(pkg string) bool {
+	if a.Package.Ecosystem != ecosystemGo {
+		return false
+	}
+	name := strings.ToLower(a.Package.Name)
+	pkg = strings.ToLower(pkg)
+	return pkg == name || strings.HasPrefix(pkg, name+"/")
+}
+
+// affectsVersion reports if the version is affected, and the lowest version fixing it.
+func (a Affected) affectsVersion(v semver.Version) (bool, string) {
+	for _, raw := range a.Versions {
+		if listed, err := semver.NewVersion(raw); err == nil && listed.Equal(v) {
+			return true, a.fixedAfter(v)
+		}
+	}
+
+	for _, r := range a.Ranges {
+		if r.Type != RangeSemver && r.Type != RangeEcosystem {
+			continue
+		}
+		if r.affects(v) {
+			return true, a.fixedAfter(v)
+		}
+	}
+	return false, ""
+}
+
+// affectsRevision reports if the revision is within the GIT ranges. Without ancestry only the
+// commits the ranges name are matched.
+func (a Affected) affectsRevision(revision string, ancestry Ancestry) bool {
+	for _, r := range a.Ranges {
+		if r.Type != RangeGit {
+			continue
+		}
+		if r.affectsRevision(revision, ancestry) {
+			return true
+		}
+	}
+	return false
+}
+
+// affectsRevision reports if the revision descends from a commit introducing the vulnerability
+// without descending from a commit fixing it since. Commits whose ancestry cannot be resolved
+// are not matched.
+func (r Range) affectsRevision(revision string, ancestry Ancestry) bool {
+	for _, e := range r.Events {
+		if revision == e.Introduced || revision == e.LastAffected {
+			return true
+		}
+	}
+	if ancestry == nil {
+		return false
+	}
+
+	isAncestor := func(ancestor, descendant string) bool {
+		if ancestor == "0" {
+			// introduced in the first commit
+			return true
+		}
+		ok, err := ancestry.IsAncestor(r.Repo, ancestor, descendant)
+		return err == nil && ok
+	}
+
+	for _, introduced := range r.Events {
+		if introduced.Introduced == "" || !isAncestor(introduced.Introduced, revision) {
+			continue
+		}
+
+		fixed := false
+		for _, e := range r.Events {
+			switch {
+			case e.Fixed != "" && isAncestor(introduced.Introduced, e.Fixed) && isAncestor(e.Fixed, revision):
+				fixed = true
+			case e.LastAffected != "" && e.LastAffected != revision && isAncestor(introduced.Introduced, e.LastAffected) && isAncestor(e.LastAffected, revision):
+				fixed = true
+			}
+		}
+		if !fixed {
+			return true
+		}
+	}
+	return false
+}
+
+// fixedAfter returns the lowest fixed version above v, or an empty string if there is none.
+func (a Affected) fixedAfter(v semver.Version) string {
+	var fixed *semver.Version
+	for _, r := range a.Ranges {
+		if r.Type != RangeSemver && r.Type != RangeEcosystem {
+			continue
+		}
+		for _, e := range r.Events {
+			f, err := semver.NewVersion(e.Fixed)
+			if err != nil || !f.GreaterThan(v) {
+				continue
+			}
+			if fixed == nil || f.LessThan(*fixed) {
+				fixed = &f
+			}
+		}
+	}
+	if fixed == nil {
+		return ""
+	}
+	return fixed.String()
+}
+
+// latestFixed returns the highest fixed version, or an empty string if there is none.
+func (a Affected) latestFixed() string {
+	var fixed *semver.Version
+	for _, r := range a.Ranges {
+		if r.Type != RangeSemver && r.Type != RangeEcosystem {
+			continue
+		}
+		for _, e := range r.Events {
+			f, err := semver.NewVersion(e.Fixed)
+			if err != nil {
+				continue
+			}
+			if fixed == nil || f.GreaterThan(*fixed) {
+				fixed = &f
+			}
+		}
+	}
+	if fixed == nil {
+		return ""
+	}
+	return fixed.String()
+}
+
+// affects evaluates the range's events in version order.
+func (r Range) affects(v semver.Version) bool {
+	type point struct {
+		version semver.Version
+		event   Event
+	}
+
+	points := make([]point, 0, len(r.Events))
+	for _, e := range r.Events {
+		raw := e.Introduced + e.Fixed + e.LastAffected
+		if raw == "0" {
+			// introduced in the first version
+			raw = "0.0.0-0"
+		}
+		pv, err := semver.NewVersion(raw)
+		if err != nil {
+			continue
+		}
+		points = append(points, point{pv, e})
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].version.LessThan(points[j].version)
+	})
+
+	affected := false
+	for _, p := range points {
+		switch {
+		case p.event.Introduced != "" && !v.LessThan(p.version):
+			affected = true
+		case p.event.Fixed != "" && !v.LessThan(p.version):
+			affected = false
+		case p.event.LastAffected != "" && v.GreaterThan(p.version):
+			affected = false
+		}
+	}
+	return affected
+}